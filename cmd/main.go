@@ -1,32 +1,261 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"students-crud/internal/config"
 	"students-crud/internal/handlers"
+	"students-crud/internal/jobs"
+	"students-crud/internal/lifecycle"
+	"students-crud/internal/logging"
+	"students-crud/internal/metrics"
+	"students-crud/internal/service"
+	"students-crud/internal/status"
 	"students-crud/internal/storage"
+	"students-crud/internal/telemetry"
 
 	"github.com/gin-gonic/gin"
 )
 
+// version версия сборки, задается при компоновке: -ldflags "-X main.version=..."
+var version = "dev"
+
+// usage справка по подкомандам
+const usage = `usage:
+  students-crud [flags]                    run the HTTP server
+  students-crud [flags] migrate up         apply all pending migrations
+  students-crud [flags] migrate down [N]   roll back N migrations (default 1)
+  students-crud [flags] migrate goto V     migrate up or down to version V
+  students-crud [flags] migrate version    print the current schema version
+  students-crud [flags] migrate force V    set version V without running migrations`
+
 func main() {
 	cfg := config.MustLoad()
 
-	storage, err := storage.New(&cfg.Storage)
+	logger := logging.New(&cfg.Logging, os.Stdout)
+	// Стандартный log и сторонние библиотеки пишут через тот же обработчик
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if len(cfg.Args) == 0 {
+		serve(ctx, stop, cfg, logger)
+		return
+	}
+
+	switch cfg.Args[0] {
+	case "migrate":
+		err := runMigrate(ctx, cfg, logger, cfg.Args[1:])
+		if errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		if err != nil {
+			logger.Error("migration failed", "error", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// serve запускает HTTP-сервер и фоновые задачи и ждет сигнала остановки
+func serve(ctx context.Context, stop context.CancelFunc, cfg *config.Config, logger *slog.Logger) {
+	if cfg.Migrations.Auto {
+		err := migrateUp(ctx, cfg, logger)
+		if err != nil {
+			logger.Error("failed to apply migrations", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	metrics := metrics.New()
+
+	tracerProvider, err := telemetry.NewTracerProvider(ctx, &cfg.Tracing, version)
 	if err != nil {
-		log.Fatalf("failed to init storage: %v", err)
+		logger.Error("failed to init tracing", "error", err)
+		os.Exit(1)
 	}
 
-	handlers := handlers.NewHandlers(storage)
+	storage, err := storage.New(&cfg.Storage, logger, metrics.QueryTracer(), telemetry.QueryTracer(tracerProvider))
+	if err != nil {
+		logger.Error("failed to init storage", "error", err)
+		os.Exit(1)
+	}
+
+	metrics.RegisterPool(storage.PoolStats)
+
+	purgeJob := jobs.NewPurgeJob(storage, cfg.Purge.Retention, cfg.Purge.Interval, logger)
+
+	adminOnly := handlers.AdminOnly(cfg.AdminToken)
+	requestID := handlers.RequestID()
+	auditContext := handlers.AuditContext(cfg.AdminToken)
+	health := handlers.NewHealth(storage, version, logger)
+
+	statusHooks := status.NewHooks()
+	statusHooks.Register("metrics", metrics.ObserveTransition)
+	students := service.NewStudentService(service.FromStorage(storage), statusHooks, logger)
+	transitions := handlers.NewTransitionHandlers(students, logger)
+	courses := handlers.NewCourseHandlers(storage, students, logger)
+	groups := handlers.NewGroupHandlers(storage, students, logger)
+
+	handlers := handlers.NewHandlers(students, logger)
+
+	middleware := []gin.HandlerFunc{
+		gin.Recovery(),
+		telemetry.Middleware(cfg.Tracing.ServiceName, tracerProvider),
+		requestID,
+		logging.Middleware(logger),
+		metrics.Middleware(),
+		auditContext,
+	}
 
-	r := gin.Default()
+	r := gin.New()
+	r.Use(middleware...)
+
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	r.GET("/healthz", health.Healthz)
+	r.GET("/readyz", health.Readyz)
+	r.GET("/status", health.Status)
 
 	r.POST("/students", handlers.CreateStudent)
+	r.GET("/students", handlers.ListStudents)
+	r.GET("/students/export", handlers.ExportStudents)
+	r.GET("/students/search", handlers.SearchStudents)
+	r.POST("/students/import", handlers.ImportStudents)
 	r.GET("/students/:id", handlers.ReadStudent)
 	r.PUT("/students/:id", handlers.UpdateStudent)
-	r.POST("/students/:id", handlers.DeleteStudent)
+	r.PATCH("/students/:id", handlers.PatchStudent)
+	r.DELETE("/students/:id", handlers.DeleteStudent)
+	r.POST("/students/:id/restore", handlers.RestoreStudent)
+	r.GET("/students/:id/history", handlers.HistoryStudent)
+	r.GET("/students/:id/courses", courses.StudentCourses)
+	r.POST("/students/:id/courses/:courseId", courses.Enroll)
+	r.DELETE("/students/:id/courses/:courseId", courses.Unenroll)
+	r.PUT("/students/:id/group", groups.MoveStudent)
+	r.GET("/students/:id/group/history", groups.GroupHistory)
+	r.POST("/students/:id/transitions", transitions.Transition)
+	r.GET("/students/:id/transitions", transitions.StatusHistory)
+
+	r.POST("/courses", courses.CreateCourse)
+	r.GET("/courses", courses.ListCourses)
+	r.GET("/courses/:id", courses.ReadCourse)
+	r.PUT("/courses/:id", courses.UpdateCourse)
+	r.DELETE("/courses/:id", courses.DeleteCourse)
+	r.GET("/courses/:id/students", courses.CourseStudents)
+
+	r.POST("/groups", groups.CreateGroup)
+	r.GET("/groups", groups.ListGroups)
+	r.GET("/groups/:id", groups.ReadGroup)
+	r.PUT("/groups/:id", groups.UpdateGroup)
+	r.DELETE("/groups/:id", groups.DeleteGroup)
+	r.GET("/groups/:id/students", groups.GroupRoster)
+
+	admin := r.Group("/admin", adminOnly)
+	admin.GET("/students", handlers.AdminListStudents)
+	admin.DELETE("/students/:id", handlers.PurgeStudent)
+
+	// gin считает двоеточие в пути началом параметра, поэтому пользовательские методы коллекции
+	// сопоставляются буквально в http.ServeMux и обслуживаются отдельным движком с теми же middleware.
+	// Остальные запросы, в том числе /students:<неизвестное действие>, получает основной роутер
+	actions := gin.New()
+	actions.Use(middleware...)
+	actions.POST("/students:batch", handlers.BatchStudents)
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /students:batch", actions)
+	mux.Handle("/", r)
+
+	srv := &http.Server{
+		Addr:              cfg.Address,
+		Handler:           mux,
+		ReadTimeout:       cfg.HTTPServer.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTPServer.ReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTPServer.WriteTimeout,
+		IdleTimeout:       cfg.HTTPServer.IdleTimeout,
+		MaxHeaderBytes:    cfg.HTTPServer.MaxHeaderBytes,
+	}
+
+	// Останавливаются в обратном порядке: сервис помечается недоступным, сервер дожидается текущих запросов,
+	// затем фоновые задачи, закрывается пул соединений и последними отправляются спаны
+	app := lifecycle.New()
+	app.Append(lifecycle.Hook{
+		Name:   "tracing",
+		OnStop: tracerProvider.Shutdown,
+	})
+	app.Append(lifecycle.Hook{
+		Name: "storage",
+		OnStop: func(context.Context) error {
+			storage.Close()
+			return nil
+		},
+	})
+	app.Append(lifecycle.Worker("purge", purgeJob.Run))
+	app.Append(lifecycle.Hook{
+		Name: "http",
+		OnStart: func(context.Context) error {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
 
-	r.Run(os.Getenv("ADDRESS"))
+			go func() {
+				err := srv.Serve(ln)
+				if err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Error("http server failed", "error", err)
+					stop()
+				}
+			}()
+
+			logger.Info("listening", "address", ln.Addr().String())
+			return nil
+		},
+		OnStop: srv.Shutdown,
+	})
+	app.Append(lifecycle.Hook{
+		Name: "readiness",
+		OnStop: func(ctx context.Context) error {
+			// Снимается первым: в течение DrainDelay /readyz отвечает 503, а сервер
+			// еще принимает запросы, пока балансировщик не исключит экземпляр
+			health.SetDraining()
+
+			select {
+			case <-time.After(cfg.HTTPServer.DrainDelay):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	err = app.Start(ctx)
+	if err != nil {
+		logger.Error("failed to start", "error", err)
+		os.Exit(1)
+	}
+
+	<-ctx.Done()
+	stop()
+	logger.Info("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTPServer.ShutdownTimeout)
+	defer cancel()
+
+	err = app.Stop(shutdownCtx)
+	if err != nil {
+		logger.Error("failed to shut down gracefully", "error", err)
+		os.Exit(1)
+	}
 }
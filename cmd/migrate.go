@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"students-crud/internal/config"
+	"students-crud/internal/storage"
+)
+
+// errUsage неверные аргументы подкоманды
+var errUsage = errors.New("invalid arguments")
+
+// runMigrate выполняет подкоманду migrate: up, down [N], goto V, version или force V
+func runMigrate(ctx context.Context, cfg *config.Config, logger *slog.Logger, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	migrator, err := storage.NewMigrator(&cfg.Storage, cfg.Migrations.LockTimeout, logger)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	command, args := args[0], args[1:]
+
+	switch {
+	case command == "up" && len(args) == 0:
+		return migrator.Up(ctx)
+
+	case command == "down" && len(args) <= 1:
+		steps := 1
+		if len(args) == 1 {
+			steps, err = strconv.Atoi(args[0])
+			if err != nil || steps < 1 {
+				return errUsage
+			}
+		}
+		return migrator.Down(ctx, steps)
+
+	case command == "goto" && len(args) == 1:
+		version, err := strconv.ParseUint(args[0], 10, 0)
+		if err != nil {
+			return errUsage
+		}
+		return migrator.Goto(ctx, uint(version))
+
+	case command == "force" && len(args) == 1:
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < -1 {
+			return errUsage
+		}
+		return migrator.Force(ctx, version)
+
+	case command == "version" && len(args) == 0:
+		version, dirty, err := migrator.Version()
+		if err != nil {
+			return err
+		}
+
+		latest, err := storage.LatestMigration()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("version: %d\nlatest: %d\ndirty: %t\n", version, latest, dirty)
+		return nil
+	}
+
+	return errUsage
+}
+
+// migrateUp применяет миграции перед запуском сервера
+func migrateUp(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
+	migrator, err := storage.NewMigrator(&cfg.Storage, cfg.Migrations.LockTimeout, logger)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	return migrator.Up(ctx)
+}
@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"context"
+	"reflect"
+
+	"students-crud/internal/models"
+)
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestIDKey
+)
+
+// ActorAdmin автор изменений, подтвержденный токеном администратора
+const ActorAdmin = "admin:token"
+
+// Unverified помечает автора, названного клиентом без проверки, и источник имени,
+// например unverified:x-actor:ivanov
+func Unverified(source, name string) string {
+	return "unverified:" + source + ":" + name
+}
+
+// WithActor сохраняет в контексте автора изменений
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// WithRequestID сохраняет в контексте идентификатор запроса
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// Actor возвращает автора изменений из контекста
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey).(string)
+	return actor
+}
+
+// RequestID возвращает идентификатор запроса из контекста
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// trackedFields поля студента, изменения которых попадают в журнал
+var trackedFields = []string{
+	"name", "email", "first_name", "last_name", "middle_name", "birth_date", "phone",
+	"enrollment_year", "status", "student_number", "group_id", "deleted_at",
+}
+
+// Diff сравнивает состояния студента до и после изменения и возвращает измененные поля.
+// nil вместо состояния означает, что записи не существовало (создание или удаление).
+func Diff(before, after *models.Student) map[string]models.FieldChange {
+	prev, next := snapshot(before), snapshot(after)
+
+	changes := make(map[string]models.FieldChange)
+	for _, field := range trackedFields {
+		if !reflect.DeepEqual(prev[field], next[field]) {
+			changes[field] = models.FieldChange{Before: prev[field], After: next[field]}
+		}
+	}
+
+	return changes
+}
+
+// snapshot представляет отслеживаемые поля студента в виде значений для сравнения
+func snapshot(s *models.Student) map[string]any {
+	if s == nil {
+		return map[string]any{}
+	}
+
+	state := map[string]any{
+		"name":  s.Name,
+		"email": s.Email,
+	}
+
+	// Незаполненные поля профиля не попадают в снимок, как и отсутствующие
+	for field, value := range map[string]string{
+		"first_name":     s.FirstName,
+		"last_name":      s.LastName,
+		"middle_name":    s.MiddleName,
+		"phone":          s.Phone,
+		"status":         s.Status,
+		"student_number": s.StudentNumber,
+	} {
+		if value != "" {
+			state[field] = value
+		}
+	}
+	if s.BirthDate != nil {
+		state["birth_date"] = s.BirthDate.String()
+	}
+	if s.EnrollmentYear != nil {
+		state["enrollment_year"] = *s.EnrollmentYear
+	}
+	if s.GroupID != nil {
+		state["group_id"] = *s.GroupID
+	}
+	if s.DeletedAt != nil {
+		state["deleted_at"] = *s.DeletedAt
+	}
+
+	return state
+}
@@ -0,0 +1,100 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"students-crud/internal/audit"
+	"students-crud/internal/models"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestDiff(t *testing.T) {
+	deletedAt := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	birthDate := models.NewDate(2004, time.May, 17)
+	student := &models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", Version: 1}
+	groupID, otherGroupID := 3, 4
+
+	testCases := []struct {
+		name     string
+		before   *models.Student
+		after    *models.Student
+		expected map[string]models.FieldChange
+	}{
+		{
+			name:  "Create",
+			after: student,
+			expected: map[string]models.FieldChange{
+				"name":  {Before: nil, After: "Student #1"},
+				"email": {Before: nil, After: "#1@mail.com"},
+			},
+		},
+		{
+			name:   "Update Email",
+			before: student,
+			after:  &models.Student{ID: 1, Name: "Student #1", Email: "new@mail.com", Version: 2},
+			expected: map[string]models.FieldChange{
+				"email": {Before: "#1@mail.com", After: "new@mail.com"},
+			},
+		},
+		{
+			name:   "Soft Delete",
+			before: student,
+			after:  &models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", Version: 2, DeletedAt: &deletedAt},
+			expected: map[string]models.FieldChange{
+				"deleted_at": {Before: nil, After: deletedAt},
+			},
+		},
+		{
+			name:   "Update Profile",
+			before: &models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", Status: models.StatusEnrolled, Version: 1},
+			after: &models.Student{
+				ID: 1, Name: "Student #1", Email: "#1@mail.com", Phone: "+79991234567",
+				BirthDate: &birthDate, Status: models.StatusOnLeave, Version: 2,
+			},
+			expected: map[string]models.FieldChange{
+				"phone":      {Before: nil, After: "+79991234567"},
+				"birth_date": {Before: nil, After: "2004-05-17"},
+				"status":     {Before: models.StatusEnrolled, After: models.StatusOnLeave},
+			},
+		},
+		{
+			name:   "Move To Group",
+			before: &models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", GroupID: &groupID, Version: 1},
+			after:  &models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", GroupID: &otherGroupID, Version: 2},
+			expected: map[string]models.FieldChange{
+				"group_id": {Before: 3, After: 4},
+			},
+		},
+		{
+			name:   "Leave Group",
+			before: &models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", GroupID: &groupID, Version: 1},
+			after:  &models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", Version: 2},
+			expected: map[string]models.FieldChange{
+				"group_id": {Before: 3, After: nil},
+			},
+		},
+		{
+			name:     "No Changes",
+			before:   student,
+			after:    &models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", Version: 2},
+			expected: map[string]models.FieldChange{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, audit.Diff(testCase.before, testCase.after))
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := audit.WithRequestID(audit.WithActor(context.Background(), "registrar"), "req-1")
+
+	assert.Equal(t, "registrar", audit.Actor(ctx))
+	assert.Equal(t, "req-1", audit.RequestID(ctx))
+	assert.Equal(t, "", audit.Actor(context.Background()))
+}
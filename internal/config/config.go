@@ -1,39 +1,215 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"flag"
+	"fmt"
 	"os"
-
-	"github.com/joho/godotenv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	Address string
+	Address    string
+	AdminToken string
+	HTTPServer
 	Storage
+	Purge
+	Tracing
+	Logging
+	Migrations
+
+	// Args позиционные аргументы после флагов, например подкоманда migrate
+	Args []string
+}
+
+// Migrations настройки применения миграций схемы
+type Migrations struct {
+	Auto        bool          // применять миграции при запуске сервера
+	LockTimeout time.Duration // сколько ждать, пока миграции выполняет другой процесс
+}
+
+// HTTPServer ограничения HTTP-сервера и время на завершение запросов при остановке
+type HTTPServer struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+	DrainDelay        time.Duration // пауза между переводом /readyz в 503 и остановкой сервера
+	MaxHeaderBytes    int
 }
 
 type Storage struct {
+	// DSN строка подключения (URL или key=value). Если задана, поля адреса и учетных данных
+	// не используются, а параметры пула, сеанса и TLS из нее важнее значений конфигурации
+	DSN      string
 	User     string
 	Password string
 	Host     string
 	Port     string
 	DB       string
+
+	// TLS; пустой SSLMode оставляет значение драйвера (prefer)
+	SSLMode     string // disable, allow, prefer, require, verify-ca или verify-full
+	SSLRootCert string // сертификат CA для проверки сервера
+	SSLCert     string // клиентский сертификат
+	SSLKey      string // ключ клиентского сертификата
+
+	// Пул соединений; нулевые значения оставляют настройки pgxpool по умолчанию
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	ConnectTimeout    time.Duration
+
+	// Параметры сеанса
+	ApplicationName  string
+	StatementTimeout time.Duration // 0 - без ограничения
+
+	// Транзакции WithTx
+	TxIsolation  string // read_committed, repeatable_read или serializable
+	TxMaxRetries int    // повторы при конфликте сериализации или взаимной блокировке
 }
 
-func MustLoad() *Config {
-	err := godotenv.Load()
+// Значения sslmode, которые понимает драйвер
+var sslModes = []string{"", "disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Уровни изоляции транзакций
+const (
+	TxReadCommitted  = "read_committed"
+	TxRepeatableRead = "repeatable_read"
+	TxSerializable   = "serializable"
+)
+
+// Форматы логов
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// Logging настройки логирования
+type Logging struct {
+	Level  string // debug, info, warn или error
+	Format string // json или text
+}
+
+// Экспортеры трассировок
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+)
+
+// Tracing настройки OpenTelemetry
+type Tracing struct {
+	Exporter     string // none, stdout или otlp
+	OTLPEndpoint string // host:port коллектора; пусто - из OTEL_EXPORTER_OTLP_ENDPOINT
+	OTLPInsecure bool   // отправлять по HTTP без TLS
+	ServiceName  string
+	SampleRatio  float64 // доля трассировок, начатых этим сервисом
+}
+
+// Purge настройки фоновой очистки мягко удаленных студентов. Очистка удаляет записи
+// безвозвратно и по умолчанию отключена; чтобы включить ее, задайте срок хранения,
+// например purge.retention: 720h в файле конфигурации или PURGE_RETENTION=720h.
+type Purge struct {
+	Retention time.Duration // 0 - очистка отключена
+	Interval  time.Duration
+}
+
+// Load собирает конфигурацию из источников в порядке возрастания приоритета:
+// значения по умолчанию, файл YAML/TOML (--config или CONFIG_FILE), файл .env,
+// переменные окружения и флаги командной строки args.
+// Для любой переменной NAME можно передать путь к файлу с секретом в NAME_FILE.
+// Все найденные ошибки возвращаются разом в *Error.
+func Load(args []string) (*Config, error) {
+	cfg := &Config{}
+	fields := fieldsOf(cfg)
+
+	problems := &Error{}
+
+	flags, configPath, positional, err := parseFlags(args, fields)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Args = positional
+
+	// Отсутствие .env не ошибка: значения могут прийти из окружения
+	err = loadDotEnv(".env")
 	if err != nil {
-		log.Panic("Error loading .env file")
+		problems.add(".env: %v", err)
+	}
+
+	if configPath == "" {
+		configPath = os.Getenv("CONFIG_FILE")
+	}
+
+	file := map[string]string{}
+	if configPath != "" {
+		file, err = readFile(configPath)
+		if err != nil {
+			problems.add("%s: %v", configPath, err)
+		}
+	}
+
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f.path] = true
+
+		value, source, err := resolve(f, file, flags)
+		if err != nil {
+			problems.add("%s: %v", source, err)
+			continue
+		}
+
+		err = f.set(value)
+		if err != nil {
+			problems.add("%s: %v", source, err)
+		}
 	}
 
-	return &Config{
-		os.Getenv("ADDRESS"),
-		Storage{
-			User:     os.Getenv("POSTGRES_USER"),
-			Password: os.Getenv("POSTGRES_PASSWORD"),
-			Host:     os.Getenv("POSTGRES_HOST"),
-			Port:     os.Getenv("POSTGRES_PORT"),
-			DB:       os.Getenv("POSTGRES_DB"),
-		},
+	for key := range file {
+		if !known[key] {
+			problems.add("%s: unknown key %q", configPath, key)
+		}
 	}
+
+	cfg.validate(problems)
+
+	if len(problems.Problems) > 0 {
+		return nil, problems
+	}
+
+	return cfg, nil
+}
+
+// MustLoad загружает конфигурацию из окружения и аргументов процесса.
+// При ошибках печатает их все и завершает процесс.
+func MustLoad() *Config {
+	cfg, err := Load(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	return cfg
+}
+
+// Error список проблем конфигурации
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+func (e *Error) add(format string, args ...any) {
+	e.Problems = append(e.Problems, fmt.Sprintf(format, args...))
 }
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+)
+
+// setBaseEnv задает минимально необходимые параметры базы
+func setBaseEnv(t *testing.T) {
+	t.Setenv("POSTGRES_USER", "students")
+	t.Setenv("POSTGRES_DB", "students")
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	setBaseEnv(t)
+
+	cfg, err := Load(nil)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, ":8080", cfg.Address)
+	assert.Equal(t, "localhost", cfg.Storage.Host)
+	assert.Equal(t, "5432", cfg.Storage.Port)
+	assert.Equal(t, 15*time.Second, cfg.HTTPServer.ReadTimeout)
+	assert.Equal(t, time.Duration(0), cfg.Purge.Retention)
+	assert.Equal(t, TracingExporterNone, cfg.Tracing.Exporter)
+	assert.Equal(t, LogFormatJSON, cfg.Logging.Format)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	setBaseEnv(t)
+
+	path := writeFile(t, "config.yaml", `
+http:
+  address: ":9000"
+  read_timeout: 30s
+storage:
+  host: db.internal
+  port: 6432
+log:
+  level: debug
+`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("POSTGRES_HOST", "db.env")
+	t.Setenv("LOG_LEVEL", "warn")
+
+	cfg, err := Load([]string{"--log-level", "error"})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, ":9000", cfg.Address)                       // файл
+	assert.Equal(t, 30*time.Second, cfg.HTTPServer.ReadTimeout) // файл
+	assert.Equal(t, "6432", cfg.Storage.Port)                   // файл
+	assert.Equal(t, "db.env", cfg.Storage.Host)                 // окружение поверх файла
+	assert.Equal(t, "error", cfg.Logging.Level)                 // флаг поверх окружения
+}
+
+func TestLoad_TOML(t *testing.T) {
+	setBaseEnv(t)
+
+	path := writeFile(t, "config.toml", `
+[tracing]
+exporter = "stdout"
+sample_ratio = 0.25
+`)
+
+	cfg, err := Load([]string{"--config", path})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, TracingExporterStdout, cfg.Tracing.Exporter)
+	assert.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+}
+
+func TestLoad_Secrets(t *testing.T) {
+	setBaseEnv(t)
+	t.Setenv("POSTGRES_PASSWORD_FILE", writeFile(t, "password", "p@ss word\n"))
+	t.Setenv("DSN", "postgres://app@db/students")
+
+	cfg, err := Load(nil)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "p@ss word", cfg.Storage.Password)
+	assert.Equal(t, "postgres://app@db/students", cfg.Storage.DSN)
+}
+
+func TestLoad_DSNReplacesFields(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://app@db/students")
+
+	cfg, err := Load(nil)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "postgres://app@db/students", cfg.Storage.DSN)
+}
+
+func TestLoad_AllProblems(t *testing.T) {
+	t.Setenv("ADDRESS", ":99999")
+	t.Setenv("POSTGRES_PORT", "0")
+	t.Setenv("HTTP_READ_TIMEOUT", "soon")
+	t.Setenv("TRACING_EXPORTER", "zipkin")
+	t.Setenv("ADMIN_TOKEN", "secret")
+	t.Setenv("ADMIN_TOKEN_FILE", "/run/secrets/admin")
+
+	_, err := Load(nil)
+
+	var cfgErr *Error
+	assert.Equal(t, true, errors.As(err, &cfgErr))
+	assert.Equal(t, []string{
+		`HTTP_READ_TIMEOUT: invalid duration "soon"`,
+		"ADMIN_TOKEN_FILE: both ADMIN_TOKEN and ADMIN_TOKEN_FILE are set",
+		"http.address: port must be between 1 and 65535",
+		"storage.port: must be between 1 and 65535",
+		"storage.db: is required unless storage.dsn is set",
+		"storage.user: is required unless storage.dsn is set",
+		"tracing.exporter: must be one of none, stdout, otlp",
+	}, cfgErr.Problems)
+}
+
+func TestLoad_UnknownFileKey(t *testing.T) {
+	setBaseEnv(t)
+	path := writeFile(t, "config.yaml", "storage:\n  hostname: db\n")
+
+	_, err := Load([]string{"--config", path})
+
+	var cfgErr *Error
+	assert.Equal(t, true, errors.As(err, &cfgErr))
+	assert.Equal(t, []string{path + `: unknown key "storage.hostname"`}, cfgErr.Problems)
+}
+
+func TestLoad_Args(t *testing.T) {
+	setBaseEnv(t)
+
+	cfg, err := Load([]string{"--log-level", "debug", "migrate", "goto", "3"})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "debug", cfg.Logging.Level)
+	assert.Equal(t, []string{"migrate", "goto", "3"}, cfg.Args)
+}
+
+func TestLoad_StorageProblems(t *testing.T) {
+	setBaseEnv(t)
+	t.Setenv("POSTGRES_SSLMODE", "strict")
+	t.Setenv("POSTGRES_SSLCERT", writeFile(t, "client.pem", "cert"))
+	t.Setenv("POSTGRES_MAX_CONNS", "4")
+	t.Setenv("POSTGRES_MIN_CONNS", "8")
+	t.Setenv("POSTGRES_TX_ISOLATION", "snapshot")
+	t.Setenv("POSTGRES_TX_MAX_RETRIES", "-1")
+
+	_, err := Load(nil)
+
+	var cfgErr *Error
+	assert.Equal(t, true, errors.As(err, &cfgErr))
+	assert.Equal(t, []string{
+		"storage.sslmode: must be one of disable, allow, prefer, require, verify-ca, verify-full",
+		"storage.sslcert: sslcert and sslkey must be set together",
+		"storage.min_conns: must not exceed storage.max_conns",
+		"storage.tx_isolation: must be one of read_committed, repeatable_read, serializable",
+		"storage.tx_max_retries: must not be negative",
+	}, cfgErr.Problems)
+}
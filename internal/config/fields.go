@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field параметр конфигурации и его имена в разных источниках
+type field struct {
+	path  string   // ключ в файле конфигурации, например "storage.host"
+	env   []string // переменные окружения; первая основная, остальные - синонимы
+	def   string   // значение по умолчанию
+	usage string
+	set   func(value string) error
+}
+
+// flagName имя флага командной строки: ключ файла с дефисами вместо точек и подчеркиваний
+func (f field) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(f.path)
+}
+
+// fieldsOf описывает все параметры конфигурации, записывая значения в cfg
+func fieldsOf(cfg *Config) []field {
+	return []field{
+		{path: "http.address", env: []string{"ADDRESS"}, def: ":8080", usage: "address to listen on", set: str(&cfg.Address)},
+		{path: "http.read_timeout", env: []string{"HTTP_READ_TIMEOUT"}, def: "15s", usage: "maximum duration for reading a request", set: duration(&cfg.HTTPServer.ReadTimeout)},
+		{path: "http.read_header_timeout", env: []string{"HTTP_READ_HEADER_TIMEOUT"}, def: "5s", usage: "maximum duration for reading request headers", set: duration(&cfg.HTTPServer.ReadHeaderTimeout)},
+		{path: "http.write_timeout", env: []string{"HTTP_WRITE_TIMEOUT"}, def: "60s", usage: "maximum duration for writing a response", set: duration(&cfg.HTTPServer.WriteTimeout)},
+		{path: "http.idle_timeout", env: []string{"HTTP_IDLE_TIMEOUT"}, def: "2m", usage: "keep-alive idle timeout", set: duration(&cfg.HTTPServer.IdleTimeout)},
+		{path: "http.shutdown_timeout", env: []string{"HTTP_SHUTDOWN_TIMEOUT"}, def: "20s", usage: "time to drain in-flight requests on shutdown", set: duration(&cfg.HTTPServer.ShutdownTimeout)},
+		{path: "http.drain_delay", env: []string{"HTTP_DRAIN_DELAY"}, def: "0s", usage: "delay between failing /readyz and stopping the server", set: duration(&cfg.HTTPServer.DrainDelay)},
+		{path: "http.max_header_bytes", env: []string{"HTTP_MAX_HEADER_BYTES"}, def: "1048576", usage: "maximum size of request headers", set: integer(&cfg.HTTPServer.MaxHeaderBytes)},
+		{path: "admin_token", env: []string{"ADMIN_TOKEN"}, usage: "bearer token for /admin endpoints", set: str(&cfg.AdminToken)},
+
+		{path: "storage.dsn", env: []string{"DATABASE_URL", "DSN"}, usage: "PostgreSQL connection string, its parameters take precedence over other storage settings", set: str(&cfg.Storage.DSN)},
+		{path: "storage.user", env: []string{"POSTGRES_USER"}, usage: "database user", set: str(&cfg.Storage.User)},
+		{path: "storage.password", env: []string{"POSTGRES_PASSWORD"}, usage: "database password", set: str(&cfg.Storage.Password)},
+		{path: "storage.host", env: []string{"POSTGRES_HOST"}, def: "localhost", usage: "database host", set: str(&cfg.Storage.Host)},
+		{path: "storage.port", env: []string{"POSTGRES_PORT"}, def: "5432", usage: "database port", set: str(&cfg.Storage.Port)},
+		{path: "storage.db", env: []string{"POSTGRES_DB"}, usage: "database name", set: str(&cfg.Storage.DB)},
+		{path: "storage.sslmode", env: []string{"POSTGRES_SSLMODE"}, usage: "TLS mode: disable, allow, prefer, require, verify-ca or verify-full", set: str(&cfg.Storage.SSLMode)},
+		{path: "storage.sslrootcert", env: []string{"POSTGRES_SSLROOTCERT"}, usage: "CA certificate file to verify the server", set: str(&cfg.Storage.SSLRootCert)},
+		{path: "storage.sslcert", env: []string{"POSTGRES_SSLCERT"}, usage: "client certificate file", set: str(&cfg.Storage.SSLCert)},
+		{path: "storage.sslkey", env: []string{"POSTGRES_SSLKEY"}, usage: "client certificate key file", set: str(&cfg.Storage.SSLKey)},
+		{path: "storage.max_conns", env: []string{"POSTGRES_MAX_CONNS"}, def: "10", usage: "maximum pool size", set: integer32(&cfg.Storage.MaxConns)},
+		{path: "storage.min_conns", env: []string{"POSTGRES_MIN_CONNS"}, def: "0", usage: "minimum number of idle connections kept open", set: integer32(&cfg.Storage.MinConns)},
+		{path: "storage.max_conn_lifetime", env: []string{"POSTGRES_MAX_CONN_LIFETIME"}, def: "1h", usage: "maximum lifetime of a connection", set: duration(&cfg.Storage.MaxConnLifetime)},
+		{path: "storage.max_conn_idle_time", env: []string{"POSTGRES_MAX_CONN_IDLE_TIME"}, def: "30m", usage: "idle time after which a connection is closed", set: duration(&cfg.Storage.MaxConnIdleTime)},
+		{path: "storage.health_check_period", env: []string{"POSTGRES_HEALTH_CHECK_PERIOD"}, def: "1m", usage: "how often idle connections are checked", set: duration(&cfg.Storage.HealthCheckPeriod)},
+		{path: "storage.connect_timeout", env: []string{"POSTGRES_CONNECT_TIMEOUT"}, def: "5s", usage: "timeout for establishing a connection", set: duration(&cfg.Storage.ConnectTimeout)},
+		{path: "storage.application_name", env: []string{"POSTGRES_APPLICATION_NAME"}, def: "students-crud", usage: "application_name reported to the server", set: str(&cfg.Storage.ApplicationName)},
+		{path: "storage.statement_timeout", env: []string{"POSTGRES_STATEMENT_TIMEOUT"}, def: "0s", usage: "default statement_timeout for sessions, 0 disables it", set: duration(&cfg.Storage.StatementTimeout)},
+		{path: "storage.tx_isolation", env: []string{"POSTGRES_TX_ISOLATION"}, def: TxReadCommitted, usage: "default transaction isolation: read_committed, repeatable_read or serializable", set: str(&cfg.Storage.TxIsolation)},
+		{path: "storage.tx_max_retries", env: []string{"POSTGRES_TX_MAX_RETRIES"}, def: "3", usage: "retries of a transaction after a serialization failure or deadlock", set: integer(&cfg.Storage.TxMaxRetries)},
+
+		{path: "migrations.auto", env: []string{"MIGRATE_AUTO"}, def: "false", usage: "apply migrations on server start", set: boolean(&cfg.Migrations.Auto)},
+		{path: "migrations.lock_timeout", env: []string{"MIGRATE_LOCK_TIMEOUT"}, def: "1m", usage: "how long to wait for another process running migrations", set: duration(&cfg.Migrations.LockTimeout)},
+
+		{path: "purge.retention", env: []string{"PURGE_RETENTION"}, def: "0s", usage: "how long soft-deleted students are kept before they are deleted permanently, 0 disables purging", set: duration(&cfg.Purge.Retention)},
+		{path: "purge.interval", env: []string{"PURGE_INTERVAL"}, def: "1h", usage: "how often the purge job runs", set: duration(&cfg.Purge.Interval)},
+
+		{path: "tracing.exporter", env: []string{"TRACING_EXPORTER"}, def: TracingExporterNone, usage: "trace exporter: none, stdout or otlp", set: str(&cfg.Tracing.Exporter)},
+		{path: "tracing.otlp_endpoint", env: []string{"TRACING_OTLP_ENDPOINT"}, usage: "OTLP collector host:port", set: str(&cfg.Tracing.OTLPEndpoint)},
+		{path: "tracing.otlp_insecure", env: []string{"TRACING_OTLP_INSECURE"}, def: "false", usage: "send traces without TLS", set: boolean(&cfg.Tracing.OTLPInsecure)},
+		{path: "tracing.service_name", env: []string{"TRACING_SERVICE_NAME"}, def: "students-crud", usage: "service name in traces", set: str(&cfg.Tracing.ServiceName)},
+		{path: "tracing.sample_ratio", env: []string{"TRACING_SAMPLE_RATIO"}, def: "1", usage: "share of traces started by this service", set: float(&cfg.Tracing.SampleRatio)},
+
+		{path: "log.level", env: []string{"LOG_LEVEL"}, def: "info", usage: "log level: debug, info, warn or error", set: str(&cfg.Logging.Level)},
+		{path: "log.format", env: []string{"LOG_FORMAT"}, def: LogFormatJSON, usage: "log format: json or text", set: str(&cfg.Logging.Format)},
+	}
+}
+
+func str(target *string) func(string) error {
+	return func(value string) error {
+		*target = value
+		return nil
+	}
+}
+
+func duration(target *time.Duration) func(string) error {
+	return func(value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		*target = d
+		return nil
+	}
+}
+
+func integer(target *int) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		*target = n
+		return nil
+	}
+}
+
+func integer32(target *int32) func(string) error {
+	return func(value string) error {
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		*target = int32(n)
+		return nil
+	}
+}
+
+func boolean(target *bool) func(string) error {
+	return func(value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		*target = b
+		return nil
+	}
+}
+
+func float(target *float64) func(string) error {
+	return func(value string) error {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		*target = f
+		return nil
+	}
+}
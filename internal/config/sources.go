@@ -0,0 +1,133 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// parseFlags разбирает флаги командной строки. Возвращает значения только явно
+// переданных флагов, путь к файлу конфигурации из --config и оставшиеся аргументы.
+func parseFlags(args []string, fields []field) (map[string]string, string, []string, error) {
+	fs := flag.NewFlagSet("students-crud", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to a YAML or TOML config file")
+	for _, f := range fields {
+		fs.String(f.flagName(), f.def, f.usage+" ($"+f.env[0]+")")
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	values := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		values[f.Name] = f.Value.String()
+	})
+
+	return values, *configPath, fs.Args(), nil
+}
+
+// loadDotEnv дополняет окружение переменными из файла, не перезаписывая уже заданные
+func loadDotEnv(path string) error {
+	err := godotenv.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// readFile читает файл конфигурации и раскладывает вложенные секции в ключи вида "storage.host"
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		return nil, errors.New("unsupported config format, use .yaml, .yml or .toml")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	flatten("", doc, values)
+
+	return values, nil
+}
+
+func flatten(prefix string, doc map[string]any, values map[string]string) {
+	for key, value := range doc {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if section, ok := value.(map[string]any); ok {
+			flatten(key, section, values)
+			continue
+		}
+
+		values[key] = fmt.Sprint(value)
+	}
+}
+
+// resolve выбирает значение параметра с наибольшим приоритетом и называет его источник
+func resolve(f field, file, flags map[string]string) (value, source string, err error) {
+	value, source = f.def, "default "+f.path
+
+	if v, ok := file[f.path]; ok {
+		value, source = v, f.path
+	}
+
+	for _, name := range f.env {
+		v, ok, err := lookupEnv(name)
+		if err != nil {
+			return "", name + "_FILE", err
+		}
+		if ok {
+			value, source = v, name
+			break
+		}
+	}
+
+	if v, ok := flags[f.flagName()]; ok {
+		value, source = v, "--"+f.flagName()
+	}
+
+	return value, source, nil
+}
+
+// lookupEnv читает переменную NAME или, если задана NAME_FILE, содержимое указанного в ней файла
+func lookupEnv(name string) (string, bool, error) {
+	value, hasValue := os.LookupEnv(name)
+	path, hasFile := os.LookupEnv(name + "_FILE")
+
+	switch {
+	case hasValue && hasFile:
+		return "", false, fmt.Errorf("both %s and %s_FILE are set", name, name)
+	case hasFile:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, err
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	default:
+		return value, hasValue && value != "", nil
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// validate проверяет согласованность значений и дописывает найденные проблемы в problems
+func (c *Config) validate(problems *Error) {
+	_, port, err := net.SplitHostPort(c.Address)
+	if err != nil {
+		problems.add("http.address: %v", err)
+	} else if !validPort(port) {
+		problems.add("http.address: port must be between 1 and 65535")
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"http.read_timeout", c.HTTPServer.ReadTimeout},
+		{"http.read_header_timeout", c.HTTPServer.ReadHeaderTimeout},
+		{"http.write_timeout", c.HTTPServer.WriteTimeout},
+		{"http.idle_timeout", c.HTTPServer.IdleTimeout},
+		{"http.shutdown_timeout", c.HTTPServer.ShutdownTimeout},
+		{"http.drain_delay", c.HTTPServer.DrainDelay},
+		{"storage.max_conn_lifetime", c.Storage.MaxConnLifetime},
+		{"storage.max_conn_idle_time", c.Storage.MaxConnIdleTime},
+		{"storage.health_check_period", c.Storage.HealthCheckPeriod},
+		{"storage.connect_timeout", c.Storage.ConnectTimeout},
+		{"storage.statement_timeout", c.Storage.StatementTimeout},
+		{"migrations.lock_timeout", c.Migrations.LockTimeout},
+		{"purge.retention", c.Purge.Retention},
+		{"purge.interval", c.Purge.Interval},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			problems.add("%s: must not be negative", d.name)
+		}
+	}
+
+	if c.HTTPServer.MaxHeaderBytes <= 0 {
+		problems.add("http.max_header_bytes: must be positive")
+	}
+
+	if c.Storage.DSN == "" {
+		if c.Storage.Host == "" {
+			problems.add("storage.host: is required unless storage.dsn is set")
+		}
+		if !validPort(c.Storage.Port) {
+			problems.add("storage.port: must be between 1 and 65535")
+		}
+		if c.Storage.DB == "" {
+			problems.add("storage.db: is required unless storage.dsn is set")
+		}
+		if c.Storage.User == "" {
+			problems.add("storage.user: is required unless storage.dsn is set")
+		}
+	}
+
+	if !slices.Contains(sslModes, c.Storage.SSLMode) {
+		problems.add("storage.sslmode: must be one of disable, allow, prefer, require, verify-ca, verify-full")
+	}
+	if (c.Storage.SSLCert == "") != (c.Storage.SSLKey == "") {
+		problems.add("storage.sslcert: sslcert and sslkey must be set together")
+	}
+	for _, file := range []struct{ name, path string }{
+		{"storage.sslrootcert", c.Storage.SSLRootCert},
+		{"storage.sslcert", c.Storage.SSLCert},
+		{"storage.sslkey", c.Storage.SSLKey},
+	} {
+		if file.path == "" {
+			continue
+		}
+		if _, err := os.Stat(file.path); err != nil {
+			problems.add("%s: %v", file.name, err)
+		}
+	}
+
+	if c.Storage.MaxConns < 0 {
+		problems.add("storage.max_conns: must not be negative")
+	}
+	if c.Storage.MinConns < 0 {
+		problems.add("storage.min_conns: must not be negative")
+	}
+	if c.Storage.MaxConns > 0 && c.Storage.MinConns > c.Storage.MaxConns {
+		problems.add("storage.min_conns: must not exceed storage.max_conns")
+	}
+	if !slices.Contains([]string{TxReadCommitted, TxRepeatableRead, TxSerializable}, c.Storage.TxIsolation) {
+		problems.add("storage.tx_isolation: must be one of read_committed, repeatable_read, serializable")
+	}
+	if c.Storage.TxMaxRetries < 0 {
+		problems.add("storage.tx_max_retries: must not be negative")
+	}
+
+	if !slices.Contains([]string{TracingExporterNone, TracingExporterStdout, TracingExporterOTLP}, c.Tracing.Exporter) {
+		problems.add("tracing.exporter: must be one of none, stdout, otlp")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problems.add("tracing.sample_ratio: must be between 0 and 1")
+	}
+	if c.Tracing.ServiceName == "" {
+		problems.add("tracing.service_name: is required")
+	}
+
+	if !slices.Contains([]string{"debug", "info", "warn", "error"}, c.Logging.Level) {
+		problems.add("log.level: must be one of debug, info, warn, error")
+	}
+	if !slices.Contains([]string{LogFormatJSON, LogFormatText}, c.Logging.Format) {
+		problems.add("log.format: must be one of json, text")
+	}
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n >= 1 && n <= 65535
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"students-crud/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	contentTypeNDJSON  = "application/x-ndjson"
+	contentTypeNDJSON2 = "application/ndjson"
+
+	maxBatchSize     = 10000
+	maxBatchBodySize = 32 << 20
+)
+
+var errBatchTooLarge = fmt.Errorf("batch must contain at most %d items", maxBatchSize)
+
+// Пакетное создание и обновление студентов (JSON-массив или NDJSON)
+func (h *Handlers) BatchStudents(ctx *gin.Context) {
+	atomic := false
+	if atomicStr := ctx.Query("atomic"); atomicStr != "" {
+		var err error
+		atomic, err = strconv.ParseBool(atomicStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid atomic flag"})
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBatchBodySize)
+
+	var (
+		items []json.RawMessage
+		err   error
+	)
+	switch ctx.ContentType() {
+	case contentTypeJSON:
+		items, err = decodeJSONBatch(body)
+	case contentTypeNDJSON, contentTypeNDJSON2:
+		items, err = decodeNDJSONBatch(body)
+	default:
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "unsupported content type"})
+		return
+	}
+
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return
+		}
+
+		h.log.DebugContext(ctx.Request.Context(), "failed to read batch", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Нераспознанный элемент передается сервису пустым: сервис отметит его как невалидный
+	// и в атомарном режиме отменит пакет, а здесь остается только заменить причину
+	students := make([]models.Student, len(items))
+	undecoded := make(map[int]bool)
+	for i, item := range items {
+		err = json.Unmarshal(item, &students[i])
+		if err != nil {
+			students[i] = models.Student{}
+			undecoded[i] = true
+		}
+	}
+
+	results, err := h.service.Upsert(ctx.Request.Context(), students, atomic)
+	if err != nil {
+		h.log.ErrorContext(ctx.Request.Context(), "failed to upsert students", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to upsert students"})
+		return
+	}
+
+	for i := range undecoded {
+		results[i].Error = "failed to unmarshal data"
+	}
+
+	summary := summarizeBatch(results)
+	if atomic && summary.Failed > 0 {
+		ctx.JSON(http.StatusUnprocessableEntity, summary)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, summary)
+}
+
+// decodeJSONBatch разбирает тело запроса как JSON-массив элементов
+func decodeJSONBatch(r io.Reader) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	err := json.NewDecoder(r).Decode(&items)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, err
+		}
+		return nil, errors.New("batch must be a JSON array")
+	}
+
+	if len(items) > maxBatchSize {
+		return nil, errBatchTooLarge
+	}
+
+	return items, nil
+}
+
+// decodeNDJSONBatch разбирает тело запроса построчно, пропуская пустые строки.
+// Ошибки в отдельных строках не прерывают разбор и попадают в результат элемента.
+func decodeNDJSONBatch(r io.Reader) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			if len(items) == maxBatchSize {
+				return nil, errBatchTooLarge
+			}
+			items = append(items, json.RawMessage(line))
+		}
+
+		if err == io.EOF {
+			return items, nil
+		}
+	}
+}
+
+// summarizeBatch подсчитывает итоги по результатам элементов
+func summarizeBatch(results []models.BatchItemResult) models.BatchResult {
+	summary := models.BatchResult{Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case models.BatchCreated:
+			summary.Created++
+		case models.BatchUpdated:
+			summary.Updated++
+		case models.BatchFailed:
+			summary.Failed++
+		}
+	}
+
+	return summary
+}
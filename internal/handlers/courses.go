@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"students-crud/internal/models"
+	"students-crud/internal/service"
+	"students-crud/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=courses.go -destination=mock/courses.go
+type CourseStorage interface {
+	CreateCourse(ctx context.Context, course *models.Course) (int, error)
+	ReadCourse(ctx context.Context, id int) (*models.Course, error)
+	ListCourses(ctx context.Context, limit, offset int) (*models.CourseList, error)
+	UpdateCourse(ctx context.Context, course *models.Course) error
+	DeleteCourse(ctx context.Context, id int) error
+	CourseStudents(ctx context.Context, courseID, limit, offset int) (*models.EnrollmentList, error)
+}
+
+// EnrollmentService записи студента на курсы; реализуется сервисом студентов
+type EnrollmentService interface {
+	Enroll(ctx context.Context, studentID, courseID int) (*models.Enrollment, error)
+	Unenroll(ctx context.Context, studentID, courseID int) error
+	StudentCourses(ctx context.Context, studentID int) ([]models.Enrollment, error)
+}
+
+// CourseHandlers обслуживает курсы и запись студентов на курсы
+type CourseHandlers struct {
+	storage  CourseStorage
+	students EnrollmentService
+	log      *slog.Logger
+}
+
+// NewCourseHandlers создает обработчики курсов; записи студентов идут через students
+func NewCourseHandlers(storage CourseStorage, students EnrollmentService, log *slog.Logger) *CourseHandlers {
+	return &CourseHandlers{storage: storage, students: students, log: log}
+}
+
+// pathID разбирает положительный целочисленный параметр пути
+func pathID(ctx *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param(name))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// bindCourse читает курс из тела запроса
+func (h *CourseHandlers) bindCourse(ctx *gin.Context) (*models.Course, bool) {
+	var course models.Course
+	err := json.NewDecoder(ctx.Request.Body).Decode(&course)
+	if err != nil {
+		h.log.DebugContext(ctx.Request.Context(), "failed to unmarshal data", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to unmarshal data"})
+		return nil, false
+	}
+
+	err = validation.Validate(&course)
+	if err != nil {
+		writeValidationError(ctx, h.log, err)
+		return nil, false
+	}
+
+	return &course, true
+}
+
+// Создание курса
+func (h *CourseHandlers) CreateCourse(ctx *gin.Context) {
+	course, ok := h.bindCourse(ctx)
+	if !ok {
+		return
+	}
+
+	id, err := h.storage.CreateCourse(ctx.Request.Context(), course)
+	if err != nil {
+		if writeDomainError(ctx, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to create course", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create course"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+// Получение курса по ID
+func (h *CourseHandlers) ReadCourse(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	course, err := h.storage.ReadCourse(ctx.Request.Context(), id)
+	if err != nil {
+		if writeDomainError(ctx, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to read course", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read course"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, course)
+}
+
+// Список курсов по семестрам
+func (h *CourseHandlers) ListCourses(ctx *gin.Context) {
+	limit, offset, err := parsePage(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.storage.ListCourses(ctx.Request.Context(), limit, offset)
+	if err != nil {
+		h.log.ErrorContext(ctx.Request.Context(), "failed to list courses", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list courses"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+// Обновление курса
+func (h *CourseHandlers) UpdateCourse(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	course, ok := h.bindCourse(ctx)
+	if !ok {
+		return
+	}
+	course.ID = id
+
+	err := h.storage.UpdateCourse(ctx.Request.Context(), course)
+	if err != nil {
+		if writeDomainError(ctx, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to update course", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update course"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "course updated successfully"})
+}
+
+// Удаление курса без записанных студентов
+func (h *CourseHandlers) DeleteCourse(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	err := h.storage.DeleteCourse(ctx.Request.Context(), id)
+	if err != nil {
+		if writeDomainError(ctx, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to delete course", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete course"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "course deleted successfully"})
+}
+
+// Студенты, записанные на курс
+func (h *CourseHandlers) CourseStudents(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	limit, offset, err := parsePage(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.storage.CourseStudents(ctx.Request.Context(), id, limit, offset)
+	if err != nil {
+		if writeDomainError(ctx, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to list course students", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list course students"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+// Курсы студента
+func (h *CourseHandlers) StudentCourses(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	enrollments, err := h.students.StudentCourses(ctx.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "student not found"})
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to list student courses", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list student courses"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"enrollments": enrollments})
+}
+
+// enrollmentIDs разбирает ID студента и курса из пути /students/:id/courses/:courseId
+func enrollmentIDs(ctx *gin.Context) (studentID, courseID int, ok bool) {
+	studentID, ok = pathID(ctx, "id")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, 0, false
+	}
+
+	courseID, ok = pathID(ctx, "courseId")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid course id"})
+		return 0, 0, false
+	}
+
+	return studentID, courseID, true
+}
+
+// Запись студента на курс
+func (h *CourseHandlers) Enroll(ctx *gin.Context) {
+	studentID, courseID, ok := enrollmentIDs(ctx)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.students.Enroll(ctx.Request.Context(), studentID, courseID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "student not found"})
+			return
+		}
+
+		if writeDomainError(ctx, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to enroll student", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enroll student"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, enrollment)
+}
+
+// Отписка студента от курса
+func (h *CourseHandlers) Unenroll(ctx *gin.Context) {
+	studentID, courseID, ok := enrollmentIDs(ctx)
+	if !ok {
+		return
+	}
+
+	err := h.students.Unenroll(ctx.Request.Context(), studentID, courseID)
+	if err != nil {
+		if writeDomainError(ctx, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to unenroll student", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unenroll student"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "student unenrolled successfully"})
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"students-crud/internal/service"
+	"students-crud/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// domainErrors сопоставляет доменные ошибки сервиса с HTTP-статусом и машиночитаемым кодом
+var domainErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{service.ErrVersionConflict, http.StatusPreconditionFailed, "version_conflict"},
+	{service.ErrEmailTaken, http.StatusConflict, "email_taken"},
+	{service.ErrNumberTaken, http.StatusConflict, "student_number_taken"},
+	{service.ErrAlreadyExists, http.StatusConflict, "already_exists"},
+	{service.ErrMissingField, http.StatusUnprocessableEntity, "missing_field"},
+	{service.ErrValueTooLong, http.StatusUnprocessableEntity, "value_too_long"},
+	{service.ErrInvalidValue, http.StatusUnprocessableEntity, "invalid_value"},
+	{service.ErrCourseNotFound, http.StatusNotFound, "course_not_found"},
+	{service.ErrEnrollmentNotFound, http.StatusNotFound, "enrollment_not_found"},
+	{service.ErrCourseExists, http.StatusConflict, "course_exists"},
+	{service.ErrCourseInUse, http.StatusConflict, "course_in_use"},
+	{service.ErrCourseFull, http.StatusConflict, "course_full"},
+	{service.ErrAlreadyEnrolled, http.StatusConflict, "already_enrolled"},
+	{service.ErrGroupNotFound, http.StatusNotFound, "group_not_found"},
+	{service.ErrGroupExists, http.StatusConflict, "group_exists"},
+	{service.ErrGroupNotEmpty, http.StatusConflict, "group_not_empty"},
+	{service.ErrIllegalTransition, http.StatusConflict, "illegal_transition"},
+	{service.ErrUnknownStatus, http.StatusUnprocessableEntity, "unknown_status"},
+}
+
+// writeDomainError отвечает клиенту, если err является доменной ошибкой сервиса
+func writeDomainError(ctx *gin.Context, err error) bool {
+	for _, d := range domainErrors {
+		if errors.Is(err, d.err) {
+			ctx.JSON(d.status, gin.H{"error": d.err.Error(), "code": d.code})
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeServiceError отвечает клиенту, если err является ошибкой проверки данных или доменной ошибкой
+func writeServiceError(ctx *gin.Context, log *slog.Logger, err error) bool {
+	var verrs validation.Errors
+	if errors.As(err, &verrs) {
+		writeValidationError(ctx, log, err)
+		return true
+	}
+
+	return writeDomainError(ctx, err)
+}
+
+// writeValidationError отвечает 422 со списком ошибок по каждому полю
+func writeValidationError(ctx *gin.Context, log *slog.Logger, err error) {
+	var verrs validation.Errors
+	if !errors.As(err, &verrs) {
+		log.ErrorContext(ctx.Request.Context(), "failed to validate request", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate request"})
+		return
+	}
+
+	ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":  "validation failed",
+		"code":   "validation_failed",
+		"fields": verrs,
+	})
+}
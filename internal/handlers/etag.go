@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"students-crud/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidETag = errors.New("invalid entity tag")
+
+// formatETag формирует сильный ETag по версии записи
+func formatETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseETags разбирает значение заголовков If-Match / If-None-Match.
+// Возвращает any=true для "*". Слабые теги (W/) учитываются только при weak=true,
+// так как If-Match требует строгого сравнения.
+func parseETags(header string, weak bool) (versions []int, any bool, err error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return nil, true, nil
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+
+		isWeak := strings.HasPrefix(tag, "W/")
+		tag = strings.TrimPrefix(tag, "W/")
+
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			return nil, false, errInvalidETag
+		}
+
+		if isWeak && !weak {
+			continue
+		}
+
+		version, err := strconv.Atoi(tag[1 : len(tag)-1])
+		if err != nil {
+			// Чужой тег не может совпасть ни с одной версией
+			continue
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, false, nil
+}
+
+// setETag передает клиенту версию записи
+func setETag(ctx *gin.Context, version int) {
+	if version > 0 {
+		ctx.Header("ETag", formatETag(version))
+	}
+}
+
+// notModified сообщает, совпадает ли версия с заголовком If-None-Match
+func notModified(ctx *gin.Context, version int) bool {
+	header := ctx.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	versions, any, err := parseETags(header, true)
+	if err != nil {
+		return false
+	}
+
+	return any || slices.Contains(versions, version)
+}
+
+// expectedVersion определяет ожидаемую версию записи по заголовку If-Match.
+// Возвращает 0, если заголовок не задан или равен "*". При ошибке ответ уже
+// отправлен клиенту и второй результат равен false.
+func (h *Handlers) expectedVersion(ctx *gin.Context, id int) (int, bool) {
+	header := ctx.GetHeader("If-Match")
+	if header == "" {
+		return 0, true
+	}
+
+	versions, any, err := parseETags(header, false)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid If-Match header"})
+		return 0, false
+	}
+
+	if any {
+		return 0, true
+	}
+
+	if len(versions) == 1 {
+		return versions[0], true
+	}
+
+	// Для списка тегов сверяемся с текущей версией и проверяем ее при записи
+	student, err := h.service.Read(ctx.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "student not found"})
+			return 0, false
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to read student", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read student"})
+		return 0, false
+	}
+
+	if !slices.Contains(versions, student.Version) {
+		writeDomainError(ctx, service.ErrVersionConflict)
+		return 0, false
+	}
+
+	return student.Version, true
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"students-crud/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// Форматы выгрузки студентов
+const (
+	contentTypeCSV  = "text/csv"
+	contentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	exportSheet = "Students"
+)
+
+// exportHeader заголовок выгружаемой таблицы, совпадает с именами полей для импорта
+var exportHeader = []string{
+	"id", "name", "email", "first_name", "last_name", "middle_name", "birth_date", "phone",
+	"enrollment_year", "status", "student_number",
+}
+
+// exportRow значения строки выгрузки в порядке exportHeader; незаполненные поля пустые
+func exportRow(s models.Student) []any {
+	row := []any{s.ID, s.Name, s.Email, s.FirstName, s.LastName, s.MiddleName, "", s.Phone, "", s.Status, s.StudentNumber}
+	if s.BirthDate != nil {
+		row[6] = s.BirthDate.String()
+	}
+	if s.EnrollmentYear != nil {
+		row[8] = *s.EnrollmentYear
+	}
+
+	return row
+}
+
+// formulaPrefixes символы, с которых табличный редактор начинает формулу в ячейке CSV
+const formulaPrefixes = "=+-@\t\r"
+
+// csvCell записывает значение ячейки CSV. Строки, которые табличный редактор принял бы
+// за формулу, экранируются апострофом; числа вроде телефона +79991234567 формулой не являются.
+// В XLSX значения записываются строковыми ячейками и не экранируются.
+func csvCell(value any) string {
+	s := fmt.Sprint(value)
+	if s == "" || !strings.ContainsRune(formulaPrefixes, rune(s[0])) {
+		return s
+	}
+
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return s
+	}
+
+	return "'" + s
+}
+
+// unescapeCell снимает экранирование csvCell, чтобы выгрузку можно было загрузить обратно.
+// Применяется только к файлам с заголовком exportHeader.
+func unescapeCell(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(s[1])) {
+		return s[1:]
+	}
+
+	return s
+}
+
+// Выгрузка всех студентов в CSV или XLSX
+func (h *Handlers) ExportStudents(ctx *gin.Context) {
+	var format string
+	switch ctx.Query("format") {
+	case "csv":
+		format = contentTypeCSV
+	case "xlsx":
+		format = contentTypeXLSX
+	case "":
+		format = ctx.NegotiateFormat(contentTypeCSV, contentTypeXLSX)
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid format"})
+		return
+	}
+
+	switch format {
+	case contentTypeCSV:
+		h.exportCSV(ctx)
+	case contentTypeXLSX:
+		h.exportXLSX(ctx)
+	default:
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "unsupported export format"})
+	}
+}
+
+// exportCSV построчно пишет студентов в ответ по мере чтения из базы
+func (h *Handlers) exportCSV(ctx *gin.Context) {
+	ctx.Header("Content-Type", contentTypeCSV+"; charset=utf-8")
+	ctx.Header("Content-Disposition", `attachment; filename="students.csv"`)
+	ctx.Status(http.StatusOK)
+
+	w := csv.NewWriter(ctx.Writer)
+	_ = w.Write(exportHeader)
+
+	err := h.service.Export(ctx.Request.Context(), func(s models.Student) error {
+		values := exportRow(s)
+		record := make([]string, len(values))
+		for i, value := range values {
+			record[i] = csvCell(value)
+		}
+		return w.Write(record)
+	})
+	if err != nil {
+		h.log.ErrorContext(ctx.Request.Context(), "failed to export students", "error", err)
+
+		// Пока ничего не отправлено, можно сообщить об ошибке обычным ответом
+		if !ctx.Writer.Written() {
+			ctx.Writer.Header().Del("Content-Disposition")
+			ctx.Writer.Header().Del("Content-Type")
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export students"})
+			return
+		}
+
+		ctx.Abort()
+		return
+	}
+
+	w.Flush()
+	if err = w.Error(); err != nil {
+		h.log.WarnContext(ctx.Request.Context(), "failed to write csv", "error", err)
+	}
+}
+
+// exportXLSX собирает книгу в потоковом режиме и отправляет ее целиком
+func (h *Handlers) exportXLSX(ctx *gin.Context) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	err := f.SetSheetName(f.GetSheetName(0), exportSheet)
+	if err != nil {
+		h.log.ErrorContext(ctx.Request.Context(), "failed to create xlsx", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export students"})
+		return
+	}
+
+	sw, err := f.NewStreamWriter(exportSheet)
+	if err != nil {
+		h.log.ErrorContext(ctx.Request.Context(), "failed to create xlsx", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export students"})
+		return
+	}
+
+	header := make([]any, len(exportHeader))
+	for i, name := range exportHeader {
+		header[i] = name
+	}
+	_ = sw.SetRow("A1", header)
+
+	row := 1
+	err = h.service.Export(ctx.Request.Context(), func(s models.Student) error {
+		row++
+		return sw.SetRow(fmt.Sprintf("A%d", row), exportRow(s))
+	})
+	if err == nil {
+		err = sw.Flush()
+	}
+	if err != nil {
+		h.log.ErrorContext(ctx.Request.Context(), "failed to export students", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export students"})
+		return
+	}
+
+	ctx.Header("Content-Type", contentTypeXLSX)
+	ctx.Header("Content-Disposition", `attachment; filename="students.xlsx"`)
+	ctx.Status(http.StatusOK)
+
+	err = f.Write(ctx.Writer)
+	if err != nil {
+		h.log.WarnContext(ctx.Request.Context(), "failed to write xlsx", "error", err)
+	}
+}
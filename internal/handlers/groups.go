@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"students-crud/internal/models"
+	"students-crud/internal/service"
+	"students-crud/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=groups.go -destination=mock/groups.go
+type GroupStorage interface {
+	CreateGroup(ctx context.Context, group *models.Group) (int, error)
+	ReadGroup(ctx context.Context, id int) (*models.Group, error)
+	ListGroups(ctx context.Context, limit, offset int) (*models.GroupList, error)
+	UpdateGroup(ctx context.Context, group *models.Group) error
+	DeleteGroup(ctx context.Context, id int) error
+	GroupRoster(ctx context.Context, groupID int, params models.RosterParams) (*models.StudentList, error)
+}
+
+// GroupMoveService переводы студента между группами; реализуется сервисом студентов
+type GroupMoveService interface {
+	MoveStudent(ctx context.Context, studentID int, move models.GroupMove) (*models.Student, error)
+	GroupHistory(ctx context.Context, studentID, limit, offset int) (*models.GroupMoveLog, error)
+}
+
+// GroupHandlers обслуживает академические группы и переводы студентов между ними
+type GroupHandlers struct {
+	storage  GroupStorage
+	students GroupMoveService
+	log      *slog.Logger
+}
+
+// NewGroupHandlers создает обработчики групп; переводы студентов идут через students
+func NewGroupHandlers(storage GroupStorage, students GroupMoveService, log *slog.Logger) *GroupHandlers {
+	return &GroupHandlers{storage: storage, students: students, log: log}
+}
+
+// bindGroup читает группу из тела запроса
+func (h *GroupHandlers) bindGroup(ctx *gin.Context) (*models.Group, bool) {
+	var group models.Group
+	err := json.NewDecoder(ctx.Request.Body).Decode(&group)
+	if err != nil {
+		h.log.DebugContext(ctx.Request.Context(), "failed to unmarshal data", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to unmarshal data"})
+		return nil, false
+	}
+
+	err = validation.Validate(&group)
+	if err != nil {
+		writeValidationError(ctx, h.log, err)
+		return nil, false
+	}
+
+	return &group, true
+}
+
+// Создание группы
+func (h *GroupHandlers) CreateGroup(ctx *gin.Context) {
+	group, ok := h.bindGroup(ctx)
+	if !ok {
+		return
+	}
+
+	id, err := h.storage.CreateGroup(ctx.Request.Context(), group)
+	if err != nil {
+		if writeDomainError(ctx, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to create group", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create group"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+// Получение группы по ID
+func (h *GroupHandlers) ReadGroup(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	group, err := h.storage.ReadGroup(ctx.Request.Context(), id)
+	if err != nil {
+		if writeDomainError(ctx, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to read group", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read group"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, group)
+}
+
+// Список групп по названию
+func (h *GroupHandlers) ListGroups(ctx *gin.Context) {
+	limit, offset, err := parsePage(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.storage.ListGroups(ctx.Request.Context(), limit, offset)
+	if err != nil {
+		h.log.ErrorContext(ctx.Request.Context(), "failed to list groups", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list groups"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+// Обновление группы
+func (h *GroupHandlers) UpdateGroup(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	group, ok := h.bindGroup(ctx)
+	if !ok {
+		return
+	}
+	group.ID = id
+
+	err := h.storage.UpdateGroup(ctx.Request.Context(), group)
+	if err != nil {
+		if writeDomainError(ctx, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to update group", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update group"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "group updated successfully"})
+}
+
+// Удаление пустой группы
+func (h *GroupHandlers) DeleteGroup(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	err := h.storage.DeleteGroup(ctx.Request.Context(), id)
+	if err != nil {
+		if writeDomainError(ctx, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to delete group", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete group"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "group deleted successfully"})
+}
+
+// Состав группы с сортировкой и постраничным выводом
+func (h *GroupHandlers) GroupRoster(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var (
+		params models.RosterParams
+		err    error
+	)
+	params.Limit, params.Offset, err = parsePage(ctx)
+	if err == nil {
+		params.SortBy, params.Desc, err = parseSort(ctx)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.storage.GroupRoster(ctx.Request.Context(), id, params)
+	if err != nil {
+		if writeDomainError(ctx, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to list group students", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list group students"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+// Перевод студента в группу или исключение из группы
+func (h *GroupHandlers) MoveStudent(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var move models.GroupMove
+	err := json.NewDecoder(ctx.Request.Body).Decode(&move)
+	if err != nil {
+		h.log.DebugContext(ctx.Request.Context(), "failed to unmarshal data", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to unmarshal data"})
+		return
+	}
+
+	student, err := h.students.MoveStudent(ctx.Request.Context(), id, move)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "student not found"})
+			return
+		}
+
+		if writeServiceError(ctx, h.log, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to move student", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to move student"})
+		return
+	}
+
+	setETag(ctx, student.Version)
+	ctx.JSON(http.StatusOK, student)
+}
+
+// История переводов студента между группами, от новых к старым
+func (h *GroupHandlers) GroupHistory(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	limit, offset, err := parsePage(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := h.students.GroupHistory(ctx.Request.Context(), id, limit, offset)
+	if err != nil {
+		h.log.ErrorContext(ctx.Request.Context(), "failed to read group history", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read group history"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
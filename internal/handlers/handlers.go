@@ -3,31 +3,47 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"students-crud/internal/models"
+	"students-crud/internal/service"
+	"students-crud/internal/validation"
 
 	"github.com/gin-gonic/gin"
 )
 
 //go:generate mockgen -source=handlers.go -destination=mock/mock.go
-type Storage interface {
+type StudentService interface {
 	Create(ctx context.Context, student *models.Student) (int, error)
 	Read(ctx context.Context, id int) (*models.Student, error)
+	List(ctx context.Context, params models.ListParams) (*models.StudentList, error)
+	Search(ctx context.Context, params models.SearchParams) (*models.SearchList, error)
 	Update(ctx context.Context, student *models.Student) error
-	Delete(ctx context.Context, id int) error
+	Patch(ctx context.Context, id int, patch models.StudentPatch) (*models.Student, error)
+	Delete(ctx context.Context, id int, version int) error
+	Restore(ctx context.Context, id int) (*models.Student, error)
+	Purge(ctx context.Context, id int) error
+	History(ctx context.Context, studentID, limit, offset int) (*models.AuditLog, error)
+	Upsert(ctx context.Context, students []models.Student, atomic bool) ([]models.BatchItemResult, error)
+	ValidateImport(students []models.Student) []models.BatchItemResult
+	Export(ctx context.Context, fn func(student models.Student) error) error
+	ApplyPatch(ctx context.Context, id, version int, build func(current *models.Student) (models.StudentPatch, error)) (*models.Student, error)
 }
 
+// Handlers переводит HTTP-запросы к студентам в вызовы сервиса; бизнес-правила живут в сервисе
 type Handlers struct {
-	storage Storage // Изменено на интерфейс
+	service StudentService
+	log     *slog.Logger
 }
 
 // NewHandlers создает новый экземпляр Handlers
-func NewHandlers(storage Storage) *Handlers { // Изменено на интерфейс
-	return &Handlers{storage: storage}
+func NewHandlers(service StudentService, log *slog.Logger) *Handlers {
+	return &Handlers{service: service, log: log}
 }
 
 // Создание нового студента
@@ -35,25 +51,30 @@ func (h *Handlers) CreateStudent(ctx *gin.Context) {
 	var s models.Student
 	jsonData, err := io.ReadAll(ctx.Request.Body)
 	if err != nil {
-		log.Println("failed to read request body")
+		h.log.DebugContext(ctx.Request.Context(), "failed to read request body", "error", err)
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
 		return
 	}
 
 	err = json.Unmarshal(jsonData, &s)
 	if err != nil {
-		log.Println("failed to unmarshal data")
+		h.log.DebugContext(ctx.Request.Context(), "failed to unmarshal data", "error", err)
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to unmarshal data"})
 		return
 	}
 
-	id, err := h.storage.Create(ctx.Request.Context(), &s)
+	id, err := h.service.Create(ctx.Request.Context(), &s)
 	if err != nil {
-		log.Println("failed to create student:", err)
+		if writeServiceError(ctx, h.log, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to create student", "error", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create student"})
 		return
 	}
 
+	setETag(ctx, s.Version)
 	ctx.JSON(http.StatusCreated, gin.H{"id": id})
 }
 
@@ -62,27 +83,181 @@ func (h *Handlers) ReadStudent(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id <= 0 {
-		log.Println("invalid id:", err)
+		h.log.DebugContext(ctx.Request.Context(), "invalid id", "error", err)
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
 
-	student, err := h.storage.Read(ctx.Request.Context(), id)
+	student, err := h.service.Read(ctx.Request.Context(), id)
 	if err != nil {
-		log.Println("failed to read student:", err)
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "student not found"})
+		if errors.Is(err, service.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "student not found"})
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to read student", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read student"})
+		return
+	}
+
+	setETag(ctx, student.Version)
+	if notModified(ctx, student.Version) {
+		ctx.Status(http.StatusNotModified)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, student)
 }
 
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// Получение списка студентов. Мягко удаленные студенты доступны только
+// администраторам через AdminListStudents.
+func (h *Handlers) ListStudents(ctx *gin.Context) {
+	if _, ok := ctx.GetQuery("with_deleted"); ok {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "with_deleted requires admin access"})
+		return
+	}
+
+	h.listStudents(ctx)
+}
+
+// Получение списка студентов вместе с мягко удаленными при with_deleted=true (только для администраторов)
+func (h *Handlers) AdminListStudents(ctx *gin.Context) {
+	h.listStudents(ctx)
+}
+
+func (h *Handlers) listStudents(ctx *gin.Context) {
+	params, err := parseListParams(ctx)
+	if err != nil {
+		h.log.DebugContext(ctx.Request.Context(), "invalid list params", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.service.List(ctx.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to list students", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list students"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+// Поиск студентов по имени и почте с учетом опечаток, от наиболее релевантных
+func (h *Handlers) SearchStudents(ctx *gin.Context) {
+	params := models.SearchParams{Query: ctx.Query("q")}
+
+	var err error
+	params.Limit, params.Offset, err = parsePage(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.service.Search(ctx.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptyQuery) || errors.Is(err, service.ErrQueryTooLong) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to search students", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search students"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+// parsePage разбирает параметры limit и offset постраничного вывода
+func parsePage(ctx *gin.Context) (limit, offset int, err error) {
+	limit = defaultListLimit
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxListLimit {
+			return 0, 0, errors.New("invalid limit")
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return 0, 0, errors.New("invalid offset")
+		}
+	}
+
+	return limit, offset, nil
+}
+
+// parseListParams разбирает параметры запроса списка студентов
+func parseListParams(ctx *gin.Context) (models.ListParams, error) {
+	params := models.ListParams{
+		Cursor:      ctx.Query("cursor"),
+		Name:        strings.TrimSpace(ctx.Query("name")),
+		EmailDomain: strings.TrimPrefix(strings.TrimSpace(ctx.Query("email_domain")), "@"),
+	}
+
+	var err error
+	params.Limit, params.Offset, err = parsePage(ctx)
+	if err != nil {
+		return params, err
+	}
+
+	if withDeleted := ctx.Query("with_deleted"); withDeleted != "" {
+		value, err := strconv.ParseBool(withDeleted)
+		if err != nil {
+			return params, errors.New("invalid with_deleted flag")
+		}
+		params.WithDeleted = value
+	}
+
+	if params.Cursor != "" && params.Offset > 0 {
+		return params, errors.New("cursor and offset are mutually exclusive")
+	}
+
+	params.SortBy, params.Desc, err = parseSort(ctx)
+	if err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
+
+// parseSort разбирает параметр sort вида name или -name; по умолчанию сортировка по ID
+func parseSort(ctx *gin.Context) (sortBy string, desc bool, err error) {
+	sort := ctx.Query("sort")
+	if sort == "" {
+		return models.SortByID, false, nil
+	}
+
+	desc = strings.HasPrefix(sort, "-")
+	sortBy = strings.TrimPrefix(sort, "-")
+
+	switch sortBy {
+	case models.SortByID, models.SortByName, models.SortByEmail:
+	default:
+		return "", false, errors.New("invalid sort")
+	}
+
+	return sortBy, desc, nil
+}
+
 // Обновление студента
 func (h *Handlers) UpdateStudent(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id <= 0 {
-		log.Println("invalid id:", err)
+		h.log.DebugContext(ctx.Request.Context(), "invalid id", "error", err)
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
@@ -90,46 +265,235 @@ func (h *Handlers) UpdateStudent(ctx *gin.Context) {
 	var s models.Student
 	jsonData, err := io.ReadAll(ctx.Request.Body)
 	if err != nil {
-		log.Println("failed to read request body")
+		h.log.DebugContext(ctx.Request.Context(), "failed to read request body", "error", err)
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
 		return
 	}
 
 	err = json.Unmarshal(jsonData, &s)
 	if err != nil {
-		log.Println("failed to unmarshal data")
+		h.log.DebugContext(ctx.Request.Context(), "failed to unmarshal data", "error", err)
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to unmarshal data"})
 		return
 	}
 
 	s.ID = id // Устанавливаем ID студента для обновления
 
-	err = h.storage.Update(ctx.Request.Context(), &s)
+	version, ok := h.expectedVersion(ctx, id)
+	if !ok {
+		return
+	}
+	s.Version = version
+
+	err = h.service.Update(ctx.Request.Context(), &s)
 	if err != nil {
-		log.Println("failed to update student:", err)
+		if errors.Is(err, service.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "student not found"})
+			return
+		}
+
+		if writeServiceError(ctx, h.log, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to update student", "error", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update student"})
 		return
 	}
 
+	setETag(ctx, s.Version)
 	ctx.JSON(http.StatusOK, gin.H{"message": "student updated successfully"})
 }
 
+// Частичное обновление студента (JSON Merge Patch или JSON Patch)
+func (h *Handlers) PatchStudent(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		h.log.DebugContext(ctx.Request.Context(), "invalid id", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	jsonData, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		h.log.DebugContext(ctx.Request.Context(), "failed to read request body", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		return
+	}
+
+	version, ok := h.expectedVersion(ctx, id)
+	if !ok {
+		return
+	}
+
+	var (
+		student  *models.Student
+		patchErr error
+	)
+	switch ctx.ContentType() {
+	case contentTypeMergePatch, contentTypeJSON:
+		var patch models.StudentPatch
+		patch, patchErr = parseMergePatch(jsonData)
+		if patchErr == nil {
+			patch.Version = version
+			student, err = h.service.Patch(ctx.Request.Context(), id, patch)
+		}
+	case contentTypeJSONPatch:
+		// Патч применяется к прочитанному состоянию, поэтому запись не должна измениться до сохранения
+		student, err = h.service.ApplyPatch(ctx.Request.Context(), id, version, func(current *models.Student) (models.StudentPatch, error) {
+			patch, err := parseJSONPatch(jsonData, current)
+			patchErr = err
+			return patch, err
+		})
+	default:
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "unsupported content type"})
+		return
+	}
+
+	if patchErr != nil {
+		var verrs validation.Errors
+		switch {
+		case errors.As(patchErr, &verrs):
+			writeValidationError(ctx, h.log, patchErr)
+		case errors.Is(patchErr, errPatchTestFailed):
+			ctx.JSON(http.StatusConflict, gin.H{"error": "patch test failed", "code": "patch_test_failed"})
+		default:
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid patch: " + patchErr.Error()})
+		}
+		return
+	}
+
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "student not found"})
+			return
+		}
+
+		if writeServiceError(ctx, h.log, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to patch student", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to patch student"})
+		return
+	}
+
+	setETag(ctx, student.Version)
+	ctx.JSON(http.StatusOK, student)
+}
+
 // Удаление студента по ID
 func (h *Handlers) DeleteStudent(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id <= 0 { // Добавлено условие проверки на id <= 0
-		log.Println("invalid id:", err)
+		h.log.DebugContext(ctx.Request.Context(), "invalid id", "error", err)
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
 
-	err = h.storage.Delete(ctx.Request.Context(), id)
+	version, ok := h.expectedVersion(ctx, id)
+	if !ok {
+		return
+	}
+
+	err = h.service.Delete(ctx.Request.Context(), id, version)
 	if err != nil {
-		log.Println("failed to delete student:", err)
+		if errors.Is(err, service.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "student not found"})
+			return
+		}
+
+		if writeDomainError(ctx, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to delete student", "error", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete student"})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "student deleted successfully"})
 }
+
+// Восстановление мягко удаленного студента
+func (h *Handlers) RestoreStudent(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		h.log.DebugContext(ctx.Request.Context(), "invalid id", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	student, err := h.service.Restore(ctx.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "deleted student not found"})
+			return
+		}
+
+		if writeDomainError(ctx, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to restore student", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore student"})
+		return
+	}
+
+	setETag(ctx, student.Version)
+	ctx.JSON(http.StatusOK, student)
+}
+
+// Безвозвратное удаление студента (только для администраторов)
+func (h *Handlers) PurgeStudent(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		h.log.DebugContext(ctx.Request.Context(), "invalid id", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	err = h.service.Purge(ctx.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "student not found"})
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to purge student", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to purge student"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "student purged successfully"})
+}
+
+// Журнал изменений студента, от новых событий к старым
+func (h *Handlers) HistoryStudent(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		h.log.DebugContext(ctx.Request.Context(), "invalid id", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	limit, offset, err := parsePage(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := h.service.History(ctx.Request.Context(), id, limit, offset)
+	if err != nil {
+		h.log.ErrorContext(ctx.Request.Context(), "failed to read student history", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read student history"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
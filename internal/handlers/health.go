@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"students-crud/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// healthCheckTimeout ограничивает проверки базы, чтобы зонд не зависал вместе с ней
+const healthCheckTimeout = 2 * time.Second
+
+//go:generate mockgen -source=health.go -destination=mock/health.go
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+	MigrationStatus(ctx context.Context) (*models.MigrationStatus, error)
+	PoolStats() models.PoolStats
+}
+
+// Health обслуживает зонды оркестратора и страницу состояния сервиса
+type Health struct {
+	checker  HealthChecker
+	version  string
+	started  time.Time
+	draining atomic.Bool
+	now      func() time.Time
+	log      *slog.Logger
+}
+
+// NewHealth создает обработчики проверок; version - версия сборки
+func NewHealth(checker HealthChecker, version string, log *slog.Logger) *Health {
+	return &Health{
+		checker: checker,
+		version: version,
+		log:     log,
+		started: time.Now(),
+		now:     time.Now,
+	}
+}
+
+// SetDraining помечает сервис выводимым из балансировки: /readyz начинает отвечать 503
+func (h *Health) SetDraining() {
+	h.draining.Store(true)
+}
+
+// Процесс жив и обрабатывает запросы
+func (h *Health) Healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Готовность принимать трафик: база доступна, схема актуальна, сервис не останавливается
+func (h *Health) Readyz(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(ctx.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	checks := gin.H{"database": "ok", "migrations": "ok", "draining": false}
+	ready := true
+
+	if h.draining.Load() {
+		checks["draining"] = true
+		ready = false
+	}
+
+	err := h.checker.Ping(c)
+	if err != nil {
+		h.log.WarnContext(ctx.Request.Context(), "readiness: database ping failed", "error", err)
+		checks["database"] = "unavailable"
+		ready = false
+	}
+
+	status, err := h.checker.MigrationStatus(c)
+	switch {
+	case err != nil:
+		h.log.WarnContext(ctx.Request.Context(), "readiness: failed to read migration status", "error", err)
+		checks["migrations"] = "unknown"
+		ready = false
+	case status.Dirty:
+		checks["migrations"] = "dirty"
+		ready = false
+	case !status.UpToDate():
+		checks["migrations"] = "outdated"
+		ready = false
+	}
+
+	if !ready {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+}
+
+// Подробное состояние сервиса: версия, время работы, пул соединений и схема
+func (h *Health) Status(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(ctx.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	uptime := h.now().Sub(h.started).Truncate(time.Second)
+
+	database := gin.H{"pool": h.checker.PoolStats()}
+
+	status, err := h.checker.MigrationStatus(c)
+	if err != nil {
+		h.log.WarnContext(ctx.Request.Context(), "status: failed to read migration status", "error", err)
+		database["migrations"] = nil
+	} else {
+		database["migrations"] = status
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"version":        h.version,
+		"started_at":     h.started.UTC(),
+		"uptime":         uptime.String(),
+		"uptime_seconds": int64(uptime.Seconds()),
+		"draining":       h.draining.Load(),
+		"database":       database,
+	})
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"students-crud/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxImportBodySize = 32 << 20
+
+// importFields поля студента, которые можно загрузить из файла. Статус в файле
+// не загружается: он задается переходами, поэтому колонка выгрузки status пропускается.
+var importFields = []string{
+	"name", "email", "first_name", "last_name", "middle_name", "birth_date", "phone", "enrollment_year", "student_number",
+}
+
+// requiredImportFields поля, колонки которых обязательны; колонки профиля могут отсутствовать
+var requiredImportFields = []string{"name", "email"}
+
+// Импорт студентов из CSV-файла (multipart/form-data, поле file)
+func (h *Handlers) ImportStudents(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportBodySize)
+
+	dryRun := false
+	if dryRunStr := ctx.DefaultPostForm("dry_run", ctx.Query("dry_run")); dryRunStr != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run flag"})
+			return
+		}
+	}
+
+	mapping, err := parseImportMapping(ctx.PostForm("mapping"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return
+		}
+
+		h.log.DebugContext(ctx.Request.Context(), "failed to read upload", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.log.DebugContext(ctx.Request.Context(), "failed to open upload", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	students, rows, results, err := readImportCSV(file, mapping)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var stored []models.BatchItemResult
+	if dryRun {
+		stored = h.service.ValidateImport(students)
+	} else {
+		stored, err = h.service.Upsert(ctx.Request.Context(), students, false)
+		if err != nil {
+			h.log.ErrorContext(ctx.Request.Context(), "failed to import students", "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import students"})
+			return
+		}
+	}
+
+	for j, result := range stored {
+		results[rows[j]].ID = result.ID
+		results[rows[j]].Status = result.Status
+		results[rows[j]].Error = result.Error
+	}
+
+	ctx.JSON(http.StatusOK, summarizeImport(results, dryRun))
+}
+
+// parseImportMapping разбирает сопоставление полей студента с колонками файла,
+// переданное JSON-объектом вида {"name": "ФИО", "email": "Почта"}.
+// Поля без сопоставления ищутся в колонке с тем же именем.
+func parseImportMapping(raw string) (map[string]string, error) {
+	mapping := make(map[string]string, len(importFields))
+	for _, field := range importFields {
+		mapping[field] = field
+	}
+
+	if raw == "" {
+		return mapping, nil
+	}
+
+	var custom map[string]string
+	err := json.Unmarshal([]byte(raw), &custom)
+	if err != nil {
+		return nil, errors.New("mapping must be a JSON object")
+	}
+
+	for field, column := range custom {
+		if _, ok := mapping[field]; !ok {
+			return nil, fmt.Errorf("unknown field %q in mapping", field)
+		}
+		mapping[field] = column
+	}
+
+	return mapping, nil
+}
+
+// readImportCSV читает строки файла; проверка студентов остается сервису. Возвращает
+// прочитанных студентов, номера их строк данных (с нуля) и заготовку отчета, где уже
+// отмечены строки, которые не удалось разобрать.
+func readImportCSV(r io.Reader, mapping map[string]string) ([]models.Student, []int, []models.ImportRowResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, nil, errors.New("failed to read CSV header")
+	}
+
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	// Экранирование формул снимается только с файлов нашей выгрузки: в чужой таблице
+	// апостроф в начале ячейки может быть частью значения
+	fromExport := slices.Equal(header, exportHeader)
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	index := make(map[string]int, len(mapping))
+	for field, column := range mapping {
+		i, ok := columns[strings.ToLower(strings.TrimSpace(column))]
+		if !ok {
+			// Колонку профиля, сопоставленную явно, файл должен содержать
+			if slices.Contains(requiredImportFields, field) || column != field {
+				return nil, nil, nil, fmt.Errorf("column %q not found", column)
+			}
+			continue
+		}
+		index[field] = i
+	}
+
+	var (
+		students []models.Student
+		rows     []int
+		results  []models.ImportRowResult
+	)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, nil, nil, fmt.Errorf("invalid CSV at line %d", parseErr.Line)
+			}
+			return nil, nil, nil, errors.New("failed to read file")
+		}
+
+		if len(results) == maxBatchSize {
+			return nil, nil, nil, fmt.Errorf("file must contain at most %d rows", maxBatchSize)
+		}
+
+		// Номер строки в файле с учетом заголовка, как его видит пользователь таблицы
+		row := len(results) + 2
+
+		s, err := importStudent(record, index, fromExport)
+		if err != nil {
+			results = append(results, models.ImportRowResult{Row: row, Status: models.BatchFailed, Error: err.Error()})
+			continue
+		}
+
+		results = append(results, models.ImportRowResult{Row: row, Status: models.BatchSkipped})
+		students = append(students, s)
+		rows = append(rows, len(results)-1)
+	}
+
+	return students, rows, results, nil
+}
+
+// importStudent собирает студента из строки файла; index - номера колонок полей.
+// Для файлов выгрузки (fromExport) снимается экранирование формул csvCell.
+func importStudent(record []string, index map[string]int, fromExport bool) (models.Student, error) {
+	value := func(field string) string {
+		i, ok := index[field]
+		if !ok {
+			return ""
+		}
+		if fromExport {
+			return unescapeCell(cell(record, i))
+		}
+		return cell(record, i)
+	}
+
+	s := models.Student{
+		Name:          value("name"),
+		Email:         value("email"),
+		FirstName:     value("first_name"),
+		LastName:      value("last_name"),
+		MiddleName:    value("middle_name"),
+		Phone:         value("phone"),
+		StudentNumber: value("student_number"),
+	}
+
+	if birthDate := strings.TrimSpace(value("birth_date")); birthDate != "" {
+		t, err := time.Parse(models.DateLayout, birthDate)
+		if err != nil {
+			return s, fmt.Errorf("birth_date must be in %s format", models.DateLayout)
+		}
+		date := models.NewDate(t.Date())
+		s.BirthDate = &date
+	}
+
+	if year := strings.TrimSpace(value("enrollment_year")); year != "" {
+		enrollmentYear, err := strconv.Atoi(year)
+		if err != nil {
+			return s, errors.New("enrollment_year must be a number")
+		}
+		s.EnrollmentYear = &enrollmentYear
+	}
+
+	return s, nil
+}
+
+// cell возвращает значение колонки или пустую строку для коротких строк
+func cell(record []string, i int) string {
+	if i < len(record) {
+		return record[i]
+	}
+
+	return ""
+}
+
+// summarizeImport подсчитывает итоги импорта
+func summarizeImport(results []models.ImportRowResult, dryRun bool) models.ImportResult {
+	summary := models.ImportResult{DryRun: dryRun, Rows: results}
+	if summary.Rows == nil {
+		summary.Rows = []models.ImportRowResult{}
+	}
+
+	for _, result := range results {
+		switch result.Status {
+		case models.BatchCreated:
+			summary.Created++
+		case models.BatchUpdated:
+			summary.Updated++
+		case models.ImportValid:
+			summary.Valid++
+		case models.BatchFailed:
+			summary.Failed++
+		}
+	}
+
+	return summary
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"students-crud/internal/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminOnly пропускает только запросы с заголовком "Authorization: Bearer <token>".
+// Пустой token закрывает доступ полностью.
+func AdminOnly(token string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		provided, ok := bearerToken(ctx, token)
+		if !provided {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// bearerToken сообщает, передан ли токен в заголовке Authorization и совпадает ли он с token
+func bearerToken(ctx *gin.Context, token string) (provided, ok bool) {
+	value, found := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if !found || value == "" {
+		return false, false
+	}
+
+	return true, token != "" && subtle.ConstantTimeCompare([]byte(value), []byte(token)) == 1
+}
+
+// headerRequestID заголовок с идентификатором запроса
+const headerRequestID = "X-Request-ID"
+
+// maxRequestIDLength ограничивает длину принятого от клиента идентификатора
+const maxRequestIDLength = 128
+
+// RequestID берет идентификатор запроса из X-Request-ID или создает новый,
+// возвращает его в ответе и сохраняет в контексте для логов и журнала изменений
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := strings.TrimSpace(ctx.GetHeader(headerRequestID))
+		if requestID == "" || len(requestID) > maxRequestIDLength || !printable(requestID) {
+			requestID = newRequestID()
+		}
+
+		ctx.Header(headerRequestID, requestID)
+		ctx.Request = ctx.Request.WithContext(audit.WithRequestID(ctx.Request.Context(), requestID))
+
+		ctx.Next()
+	}
+}
+
+// newRequestID создает случайный идентификатор из 16 байт в шестнадцатеричном виде
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// printable проверяет, что в строке только видимые ASCII-символы
+func printable(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x21 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// maxActorLength ограничивает длину автора из X-Actor
+const maxActorLength = 128
+
+// AuditContext передает в контекст запроса автора изменений, чтобы хранилище записало его в журнал.
+// Запрос с токеном администратора записывается от audit.ActorAdmin. Заголовок X-Actor
+// ничем не подтвержден, поэтому его значение записывается с пометкой audit.Unverified.
+func AuditContext(adminToken string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var actor string
+		if _, ok := bearerToken(ctx, adminToken); ok {
+			actor = audit.ActorAdmin
+		} else if name := strings.TrimSpace(ctx.GetHeader("X-Actor")); name != "" && utf8.RuneCountInString(name) <= maxActorLength {
+			actor = audit.Unverified("x-actor", name)
+		}
+
+		if actor != "" {
+			ctx.Request = ctx.Request.WithContext(audit.WithActor(ctx.Request.Context(), actor))
+		}
+
+		ctx.Next()
+	}
+}
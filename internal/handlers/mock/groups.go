@@ -0,0 +1,177 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: groups.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	reflect "reflect"
+	models "students-crud/internal/models"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockGroupStorage is a mock of GroupStorage interface.
+type MockGroupStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockGroupStorageMockRecorder
+}
+
+// MockGroupStorageMockRecorder is the mock recorder for MockGroupStorage.
+type MockGroupStorageMockRecorder struct {
+	mock *MockGroupStorage
+}
+
+// NewMockGroupStorage creates a new mock instance.
+func NewMockGroupStorage(ctrl *gomock.Controller) *MockGroupStorage {
+	mock := &MockGroupStorage{ctrl: ctrl}
+	mock.recorder = &MockGroupStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGroupStorage) EXPECT() *MockGroupStorageMockRecorder {
+	return m.recorder
+}
+
+// CreateGroup mocks base method.
+func (m *MockGroupStorage) CreateGroup(ctx context.Context, group *models.Group) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGroup", ctx, group)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGroup indicates an expected call of CreateGroup.
+func (mr *MockGroupStorageMockRecorder) CreateGroup(ctx, group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroup", reflect.TypeOf((*MockGroupStorage)(nil).CreateGroup), ctx, group)
+}
+
+// DeleteGroup mocks base method.
+func (m *MockGroupStorage) DeleteGroup(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGroup", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGroup indicates an expected call of DeleteGroup.
+func (mr *MockGroupStorageMockRecorder) DeleteGroup(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGroup", reflect.TypeOf((*MockGroupStorage)(nil).DeleteGroup), ctx, id)
+}
+
+// GroupRoster mocks base method.
+func (m *MockGroupStorage) GroupRoster(ctx context.Context, groupID int, params models.RosterParams) (*models.StudentList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GroupRoster", ctx, groupID, params)
+	ret0, _ := ret[0].(*models.StudentList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GroupRoster indicates an expected call of GroupRoster.
+func (mr *MockGroupStorageMockRecorder) GroupRoster(ctx, groupID, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GroupRoster", reflect.TypeOf((*MockGroupStorage)(nil).GroupRoster), ctx, groupID, params)
+}
+
+// ListGroups mocks base method.
+func (m *MockGroupStorage) ListGroups(ctx context.Context, limit, offset int) (*models.GroupList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGroups", ctx, limit, offset)
+	ret0, _ := ret[0].(*models.GroupList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGroups indicates an expected call of ListGroups.
+func (mr *MockGroupStorageMockRecorder) ListGroups(ctx, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGroups", reflect.TypeOf((*MockGroupStorage)(nil).ListGroups), ctx, limit, offset)
+}
+
+// ReadGroup mocks base method.
+func (m *MockGroupStorage) ReadGroup(ctx context.Context, id int) (*models.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadGroup", ctx, id)
+	ret0, _ := ret[0].(*models.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadGroup indicates an expected call of ReadGroup.
+func (mr *MockGroupStorageMockRecorder) ReadGroup(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadGroup", reflect.TypeOf((*MockGroupStorage)(nil).ReadGroup), ctx, id)
+}
+
+// UpdateGroup mocks base method.
+func (m *MockGroupStorage) UpdateGroup(ctx context.Context, group *models.Group) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGroup", ctx, group)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateGroup indicates an expected call of UpdateGroup.
+func (mr *MockGroupStorageMockRecorder) UpdateGroup(ctx, group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGroup", reflect.TypeOf((*MockGroupStorage)(nil).UpdateGroup), ctx, group)
+}
+
+// MockGroupMoveService is a mock of GroupMoveService interface.
+type MockGroupMoveService struct {
+	ctrl     *gomock.Controller
+	recorder *MockGroupMoveServiceMockRecorder
+}
+
+// MockGroupMoveServiceMockRecorder is the mock recorder for MockGroupMoveService.
+type MockGroupMoveServiceMockRecorder struct {
+	mock *MockGroupMoveService
+}
+
+// NewMockGroupMoveService creates a new mock instance.
+func NewMockGroupMoveService(ctrl *gomock.Controller) *MockGroupMoveService {
+	mock := &MockGroupMoveService{ctrl: ctrl}
+	mock.recorder = &MockGroupMoveServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGroupMoveService) EXPECT() *MockGroupMoveServiceMockRecorder {
+	return m.recorder
+}
+
+// GroupHistory mocks base method.
+func (m *MockGroupMoveService) GroupHistory(ctx context.Context, studentID, limit, offset int) (*models.GroupMoveLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GroupHistory", ctx, studentID, limit, offset)
+	ret0, _ := ret[0].(*models.GroupMoveLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GroupHistory indicates an expected call of GroupHistory.
+func (mr *MockGroupMoveServiceMockRecorder) GroupHistory(ctx, studentID, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GroupHistory", reflect.TypeOf((*MockGroupMoveService)(nil).GroupHistory), ctx, studentID, limit, offset)
+}
+
+// MoveStudent mocks base method.
+func (m *MockGroupMoveService) MoveStudent(ctx context.Context, studentID int, move models.GroupMove) (*models.Student, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveStudent", ctx, studentID, move)
+	ret0, _ := ret[0].(*models.Student)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveStudent indicates an expected call of MoveStudent.
+func (mr *MockGroupMoveServiceMockRecorder) MoveStudent(ctx, studentID, move interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveStudent", reflect.TypeOf((*MockGroupMoveService)(nil).MoveStudent), ctx, studentID, move)
+}
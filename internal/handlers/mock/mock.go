@@ -1,8 +1,8 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: internal/handlers/handlers.go
+// Source: handlers.go
 
-// Package mock_storage is a generated GoMock package.
-package mock_storage
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
 
 import (
 	context "context"
@@ -12,31 +12,46 @@ import (
 	gomock "github.com/golang/mock/gomock"
 )
 
-// MockStorage is a mock of Storage interface.
-type MockStorage struct {
+// MockStudentService is a mock of StudentService interface.
+type MockStudentService struct {
 	ctrl     *gomock.Controller
-	recorder *MockStorageMockRecorder
+	recorder *MockStudentServiceMockRecorder
 }
 
-// MockStorageMockRecorder is the mock recorder for MockStorage.
-type MockStorageMockRecorder struct {
-	mock *MockStorage
+// MockStudentServiceMockRecorder is the mock recorder for MockStudentService.
+type MockStudentServiceMockRecorder struct {
+	mock *MockStudentService
 }
 
-// NewMockStorage creates a new mock instance.
-func NewMockStorage(ctrl *gomock.Controller) *MockStorage {
-	mock := &MockStorage{ctrl: ctrl}
-	mock.recorder = &MockStorageMockRecorder{mock}
+// NewMockStudentService creates a new mock instance.
+func NewMockStudentService(ctrl *gomock.Controller) *MockStudentService {
+	mock := &MockStudentService{ctrl: ctrl}
+	mock.recorder = &MockStudentServiceMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
+func (m *MockStudentService) EXPECT() *MockStudentServiceMockRecorder {
 	return m.recorder
 }
 
+// ApplyPatch mocks base method.
+func (m *MockStudentService) ApplyPatch(ctx context.Context, id, version int, build func(*models.Student) (models.StudentPatch, error)) (*models.Student, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyPatch", ctx, id, version, build)
+	ret0, _ := ret[0].(*models.Student)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyPatch indicates an expected call of ApplyPatch.
+func (mr *MockStudentServiceMockRecorder) ApplyPatch(ctx, id, version, build interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyPatch", reflect.TypeOf((*MockStudentService)(nil).ApplyPatch), ctx, id, version, build)
+}
+
 // Create mocks base method.
-func (m *MockStorage) Create(ctx context.Context, student *models.Student) (int, error) {
+func (m *MockStudentService) Create(ctx context.Context, student *models.Student) (int, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Create", ctx, student)
 	ret0, _ := ret[0].(int)
@@ -45,27 +60,100 @@ func (m *MockStorage) Create(ctx context.Context, student *models.Student) (int,
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockStorageMockRecorder) Create(ctx, student interface{}) *gomock.Call {
+func (mr *MockStudentServiceMockRecorder) Create(ctx, student interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockStorage)(nil).Create), ctx, student)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockStudentService)(nil).Create), ctx, student)
 }
 
 // Delete mocks base method.
-func (m *MockStorage) Delete(ctx context.Context, id int) error {
+func (m *MockStudentService) Delete(ctx context.Context, id, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockStorageMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+func (mr *MockStudentServiceMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockStudentService)(nil).Delete), ctx, id, version)
+}
+
+// Export mocks base method.
+func (m *MockStudentService) Export(ctx context.Context, fn func(models.Student) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockStudentServiceMockRecorder) Export(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockStudentService)(nil).Export), ctx, fn)
+}
+
+// History mocks base method.
+func (m *MockStudentService) History(ctx context.Context, studentID, limit, offset int) (*models.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, studentID, limit, offset)
+	ret0, _ := ret[0].(*models.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockStudentServiceMockRecorder) History(ctx, studentID, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockStudentService)(nil).History), ctx, studentID, limit, offset)
+}
+
+// List mocks base method.
+func (m *MockStudentService) List(ctx context.Context, params models.ListParams) (*models.StudentList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, params)
+	ret0, _ := ret[0].(*models.StudentList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockStudentServiceMockRecorder) List(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockStudentService)(nil).List), ctx, params)
+}
+
+// Patch mocks base method.
+func (m *MockStudentService) Patch(ctx context.Context, id int, patch models.StudentPatch) (*models.Student, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", ctx, id, patch)
+	ret0, _ := ret[0].(*models.Student)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockStudentServiceMockRecorder) Patch(ctx, id, patch interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockStorage)(nil).Delete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockStudentService)(nil).Patch), ctx, id, patch)
+}
+
+// Purge mocks base method.
+func (m *MockStudentService) Purge(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockStudentServiceMockRecorder) Purge(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockStudentService)(nil).Purge), ctx, id)
 }
 
 // Read mocks base method.
-func (m *MockStorage) Read(ctx context.Context, id int) (*models.Student, error) {
+func (m *MockStudentService) Read(ctx context.Context, id int) (*models.Student, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Read", ctx, id)
 	ret0, _ := ret[0].(*models.Student)
@@ -74,13 +162,43 @@ func (m *MockStorage) Read(ctx context.Context, id int) (*models.Student, error)
 }
 
 // Read indicates an expected call of Read.
-func (mr *MockStorageMockRecorder) Read(ctx, id interface{}) *gomock.Call {
+func (mr *MockStudentServiceMockRecorder) Read(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockStorage)(nil).Read), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockStudentService)(nil).Read), ctx, id)
+}
+
+// Restore mocks base method.
+func (m *MockStudentService) Restore(ctx context.Context, id int) (*models.Student, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(*models.Student)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockStudentServiceMockRecorder) Restore(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockStudentService)(nil).Restore), ctx, id)
+}
+
+// Search mocks base method.
+func (m *MockStudentService) Search(ctx context.Context, params models.SearchParams) (*models.SearchList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, params)
+	ret0, _ := ret[0].(*models.SearchList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockStudentServiceMockRecorder) Search(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockStudentService)(nil).Search), ctx, params)
 }
 
 // Update mocks base method.
-func (m *MockStorage) Update(ctx context.Context, student *models.Student) error {
+func (m *MockStudentService) Update(ctx context.Context, student *models.Student) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Update", ctx, student)
 	ret0, _ := ret[0].(error)
@@ -88,7 +206,36 @@ func (m *MockStorage) Update(ctx context.Context, student *models.Student) error
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockStorageMockRecorder) Update(ctx, student interface{}) *gomock.Call {
+func (mr *MockStudentServiceMockRecorder) Update(ctx, student interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockStudentService)(nil).Update), ctx, student)
+}
+
+// Upsert mocks base method.
+func (m *MockStudentService) Upsert(ctx context.Context, students []models.Student, atomic bool) ([]models.BatchItemResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, students, atomic)
+	ret0, _ := ret[0].([]models.BatchItemResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockStudentServiceMockRecorder) Upsert(ctx, students, atomic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockStudentService)(nil).Upsert), ctx, students, atomic)
+}
+
+// ValidateImport mocks base method.
+func (m *MockStudentService) ValidateImport(students []models.Student) []models.BatchItemResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateImport", students)
+	ret0, _ := ret[0].([]models.BatchItemResult)
+	return ret0
+}
+
+// ValidateImport indicates an expected call of ValidateImport.
+func (mr *MockStudentServiceMockRecorder) ValidateImport(students interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockStorage)(nil).Update), ctx, student)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateImport", reflect.TypeOf((*MockStudentService)(nil).ValidateImport), students)
 }
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: transitions.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	reflect "reflect"
+	models "students-crud/internal/models"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTransitionService is a mock of TransitionService interface.
+type MockTransitionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransitionServiceMockRecorder
+}
+
+// MockTransitionServiceMockRecorder is the mock recorder for MockTransitionService.
+type MockTransitionServiceMockRecorder struct {
+	mock *MockTransitionService
+}
+
+// NewMockTransitionService creates a new mock instance.
+func NewMockTransitionService(ctrl *gomock.Controller) *MockTransitionService {
+	mock := &MockTransitionService{ctrl: ctrl}
+	mock.recorder = &MockTransitionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransitionService) EXPECT() *MockTransitionServiceMockRecorder {
+	return m.recorder
+}
+
+// StatusHistory mocks base method.
+func (m *MockTransitionService) StatusHistory(ctx context.Context, studentID, limit, offset int) (*models.StatusHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatusHistory", ctx, studentID, limit, offset)
+	ret0, _ := ret[0].(*models.StatusHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatusHistory indicates an expected call of StatusHistory.
+func (mr *MockTransitionServiceMockRecorder) StatusHistory(ctx, studentID, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatusHistory", reflect.TypeOf((*MockTransitionService)(nil).StatusHistory), ctx, studentID, limit, offset)
+}
+
+// Transition mocks base method.
+func (m *MockTransitionService) Transition(ctx context.Context, studentID int, input models.TransitionInput) (*models.StatusTransition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transition", ctx, studentID, input)
+	ret0, _ := ret[0].(*models.StatusTransition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transition indicates an expected call of Transition.
+func (mr *MockTransitionServiceMockRecorder) Transition(ctx, studentID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transition", reflect.TypeOf((*MockTransitionService)(nil).Transition), ctx, studentID, input)
+}
@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"students-crud/internal/models"
+	"students-crud/internal/validation"
+)
+
+// Типы содержимого, поддерживаемые PATCH /students/:id
+const (
+	contentTypeJSON       = "application/json"
+	contentTypeMergePatch = "application/merge-patch+json"
+	contentTypeJSONPatch  = "application/json-patch+json"
+)
+
+var errPatchTestFailed = errors.New("patch test failed")
+
+// patchFields изменяемые поля студента, доступные через PATCH
+var patchFields = []string{
+	"name", "email", "first_name", "last_name", "middle_name", "birth_date", "phone", "enrollment_year",
+}
+
+// readOnlyFields поля студента, которые возвращаются клиенту, но не изменяются через PATCH.
+// Статус меняется переходом через POST /students/:id/transitions.
+var readOnlyFields = []string{"id", "status", "student_number", "group_id", "created_at", "updated_at", "deleted_at"}
+
+// parseMergePatch разбирает документ JSON Merge Patch (RFC 7396)
+func parseMergePatch(data []byte) (models.StudentPatch, error) {
+	var patch models.StudentPatch
+
+	var doc map[string]json.RawMessage
+	err := json.Unmarshal(data, &doc)
+	if err != nil || doc == nil {
+		return patch, errors.New("merge patch must be a JSON object")
+	}
+
+	for key, raw := range doc {
+		if slices.Contains(readOnlyFields, key) {
+			return patch, fmt.Errorf("%s is read-only", key)
+		}
+
+		// null в merge patch означает удаление поля
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			err = clearField(&patch, key)
+		} else {
+			err = setField(&patch, key, raw)
+		}
+		if err != nil {
+			return patch, err
+		}
+	}
+
+	return patch, nil
+}
+
+// setField записывает в патч значение поля key
+func setField(patch *models.StudentPatch, key string, raw json.RawMessage) error {
+	var target **string
+	switch key {
+	case "name":
+		target = &patch.Name
+	case "email":
+		target = &patch.Email
+	case "first_name":
+		target = &patch.FirstName
+	case "last_name":
+		target = &patch.LastName
+	case "middle_name":
+		target = &patch.MiddleName
+	case "phone":
+		target = &patch.Phone
+	case "birth_date":
+		var value models.Date
+		err := json.Unmarshal(raw, &value)
+		if err != nil {
+			return fmt.Errorf("birth_date: %w", err)
+		}
+		patch.BirthDate = &value
+		return nil
+	case "enrollment_year":
+		var value int
+		err := json.Unmarshal(raw, &value)
+		if err != nil {
+			return errors.New("enrollment_year must be an integer")
+		}
+		patch.EnrollmentYear = &value
+		return nil
+	default:
+		return fmt.Errorf("unknown field %q", key)
+	}
+
+	var value string
+	err := json.Unmarshal(raw, &value)
+	if err != nil {
+		return fmt.Errorf("%s must be a string", key)
+	}
+	*target = &value
+
+	return nil
+}
+
+// clearField очищает необязательное поле; обязательные поля удалить нельзя
+func clearField(patch *models.StudentPatch, key string) error {
+	empty := ""
+	switch key {
+	case "name", "email":
+		return validation.Errors{{Field: key, Rule: "required", Message: "is required"}}
+	case "first_name":
+		patch.FirstName = &empty
+	case "last_name":
+		patch.LastName = &empty
+	case "middle_name":
+		patch.MiddleName = &empty
+	case "phone":
+		patch.Phone = &empty
+	case models.FieldBirthDate, models.FieldEnrollmentYear:
+		patch.Clear = append(patch.Clear, key)
+	default:
+		return fmt.Errorf("unknown field %q", key)
+	}
+
+	return nil
+}
+
+// patchOperation операция JSON Patch (RFC 6902)
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// parseJSONPatch применяет документ JSON Patch к текущему состоянию студента
+// и возвращает изменения в виде StudentPatch
+func parseJSONPatch(data []byte, current *models.Student) (models.StudentPatch, error) {
+	var patch models.StudentPatch
+
+	var ops []patchOperation
+	err := json.Unmarshal(data, &ops)
+	if err != nil {
+		return patch, errors.New("json patch must be an array of operations")
+	}
+
+	doc, err := toDocument(current)
+	if err != nil {
+		return patch, err
+	}
+
+	for i, op := range ops {
+		err = applyOperation(doc, op)
+		if err != nil {
+			if errors.Is(err, errPatchTestFailed) {
+				return patch, err
+			}
+			return patch, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+
+	original, err := toDocument(current)
+	if err != nil {
+		return patch, err
+	}
+
+	// Результат сводится к merge patch из полей, отличающихся от исходного состояния
+	keys := make([]string, 0, len(doc)+len(original))
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	for key := range original {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	changes := make(map[string]any)
+	for _, key := range slices.Compact(keys) {
+		if reflect.DeepEqual(doc[key], original[key]) {
+			continue
+		}
+
+		switch {
+		case slices.Contains(readOnlyFields, key):
+			return patch, fmt.Errorf("%s is read-only", key)
+		case !slices.Contains(patchFields, key):
+			return patch, fmt.Errorf("unknown field %q", key)
+		}
+		changes[key] = doc[key]
+	}
+
+	data, err = json.Marshal(changes)
+	if err != nil {
+		return patch, err
+	}
+
+	return parseMergePatch(data)
+}
+
+// toDocument представляет студента в виде JSON-объекта для применения операций.
+// Все изменяемые поля присутствуют в документе, незаполненные - со значением null,
+// чтобы к ним применялись replace, test и remove.
+func toDocument(student *models.Student) (map[string]any, error) {
+	data, err := json.Marshal(student)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+	err = json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range patchFields {
+		if _, ok := doc[field]; !ok {
+			doc[field] = nil
+		}
+	}
+
+	return doc, nil
+}
+
+// applyOperation применяет одну операцию JSON Patch к плоскому документу
+func applyOperation(doc map[string]any, op patchOperation) error {
+	key, err := parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		value, err := decodeValue(op.Value)
+		if err != nil {
+			return err
+		}
+
+		current, exists := doc[key]
+		if op.Op != "add" && !exists {
+			return fmt.Errorf("path %q does not exist", op.Path)
+		}
+
+		if op.Op == "test" {
+			if !reflect.DeepEqual(current, value) {
+				return errPatchTestFailed
+			}
+			return nil
+		}
+
+		doc[key] = value
+	case "remove":
+		if _, exists := doc[key]; !exists {
+			return fmt.Errorf("path %q does not exist", op.Path)
+		}
+		delete(doc, key)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return err
+		}
+
+		value, exists := doc[from]
+		if !exists {
+			return fmt.Errorf("path %q does not exist", op.From)
+		}
+
+		if op.Op == "move" {
+			delete(doc, from)
+		}
+		doc[key] = value
+	default:
+		return fmt.Errorf("unsupported op %q", op.Op)
+	}
+
+	return nil
+}
+
+// parsePointer разбирает JSON Pointer (RFC 6901), указывающий на поле верхнего уровня
+func parsePointer(pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") || strings.Count(pointer, "/") != 1 {
+		return "", fmt.Errorf("unsupported path %q", pointer)
+	}
+
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(pointer[1:]), nil
+}
+
+// decodeValue разбирает значение операции в тех же типах, что и документ
+func decodeValue(raw json.RawMessage) (any, error) {
+	if raw == nil {
+		return nil, errors.New("value is required")
+	}
+
+	var value any
+	err := json.Unmarshal(raw, &value)
+	if err != nil {
+		return nil, errors.New("invalid value")
+	}
+
+	return value, nil
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"students-crud/internal/models"
+	"students-crud/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=transitions.go -destination=mock/transitions.go
+type TransitionService interface {
+	Transition(ctx context.Context, studentID int, input models.TransitionInput) (*models.StatusTransition, error)
+	StatusHistory(ctx context.Context, studentID, limit, offset int) (*models.StatusHistory, error)
+}
+
+// TransitionHandlers обслуживает смену статуса обучения студента
+type TransitionHandlers struct {
+	service TransitionService
+	log     *slog.Logger
+}
+
+// NewTransitionHandlers создает обработчики смены статуса
+func NewTransitionHandlers(service TransitionService, log *slog.Logger) *TransitionHandlers {
+	return &TransitionHandlers{service: service, log: log}
+}
+
+// Смена статуса студента с указанием причины
+func (h *TransitionHandlers) Transition(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var input models.TransitionInput
+	err := json.NewDecoder(ctx.Request.Body).Decode(&input)
+	if err != nil {
+		h.log.DebugContext(ctx.Request.Context(), "failed to unmarshal data", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to unmarshal data"})
+		return
+	}
+
+	transition, err := h.service.Transition(ctx.Request.Context(), id, input)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "student not found"})
+			return
+		}
+
+		if writeServiceError(ctx, h.log, err) {
+			return
+		}
+
+		h.log.ErrorContext(ctx.Request.Context(), "failed to change student status", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to change student status"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, transition)
+}
+
+// История смены статуса студента, от новых переходов к старым
+func (h *TransitionHandlers) StatusHistory(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	limit, offset, err := parsePage(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := h.service.StatusHistory(ctx.Request.Context(), id, limit, offset)
+	if err != nil {
+		h.log.ErrorContext(ctx.Request.Context(), "failed to read status history", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read status history"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"students-crud/internal/audit"
+)
+
+// purgeActor автор событий журнала, созданных задачей очистки
+const purgeActor = "system:purge"
+
+type Purger interface {
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+}
+
+// PurgeJob периодически удаляет студентов, мягко удаленных раньше срока хранения
+type PurgeJob struct {
+	storage   Purger
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+	log       *slog.Logger
+}
+
+// NewPurgeJob создает задачу очистки
+func NewPurgeJob(storage Purger, retention, interval time.Duration, log *slog.Logger) *PurgeJob {
+	return &PurgeJob{
+		storage:   storage,
+		retention: retention,
+		interval:  interval,
+		now:       time.Now,
+		log:       log,
+	}
+}
+
+// Run выполняет очистку сразу и затем раз в interval, пока не отменен ctx.
+// При нулевом сроке хранения или интервале задача ничего не делает.
+func (j *PurgeJob) Run(ctx context.Context) {
+	if j.retention <= 0 || j.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce удаляет записи, срок хранения которых истек
+func (j *PurgeJob) RunOnce(ctx context.Context) {
+	purged, err := j.storage.PurgeDeleted(audit.WithActor(ctx, purgeActor), j.now().Add(-j.retention))
+	if err != nil {
+		j.log.ErrorContext(ctx, "failed to purge deleted students", "error", err)
+		return
+	}
+
+	if purged > 0 {
+		j.log.InfoContext(ctx, "purged deleted students", "count", purged)
+	}
+}
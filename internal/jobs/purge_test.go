@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+type fakePurger struct {
+	before []time.Time
+	err    error
+}
+
+func (f *fakePurger) PurgeDeleted(_ context.Context, before time.Time) (int64, error) {
+	f.before = append(f.before, before)
+	return 1, f.err
+}
+
+func TestPurgeJob_RunOnce(t *testing.T) {
+	now := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		retention time.Duration
+		err       error
+	}{
+		{name: "OK", retention: 24 * time.Hour},
+		{name: "Storage Error", retention: time.Hour, err: errors.New("connection refused")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			purger := &fakePurger{err: testCase.err}
+
+			job := NewPurgeJob(purger, testCase.retention, time.Minute, testLogger)
+			job.now = func() time.Time { return now }
+
+			job.RunOnce(context.Background())
+
+			assert.Equal(t, []time.Time{now.Add(-testCase.retention)}, purger.before)
+		})
+	}
+}
+
+func TestPurgeJob_RunDisabled(t *testing.T) {
+	purger := &fakePurger{}
+
+	NewPurgeJob(purger, 0, time.Minute, testLogger).Run(context.Background())
+
+	assert.Equal(t, 0, len(purger.before))
+}
@@ -0,0 +1,120 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Hook часть приложения с управляемым временем жизни.
+// OnStart не должен блокироваться; долгую работу он запускает в отдельной горутине.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Lifecycle запускает части приложения в порядке добавления и останавливает в обратном,
+// чтобы зависимые части завершались раньше тех, от которых зависят
+type Lifecycle struct {
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+}
+
+// New создает пустой Lifecycle
+func New() *Lifecycle {
+	return &Lifecycle{}
+}
+
+// Append добавляет часть приложения
+func (l *Lifecycle) Append(hook Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, hook)
+}
+
+// Start запускает части приложения по порядку. Если одна из них не запустилась,
+// уже запущенные останавливаются и возвращается ошибка запуска.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for l.started < len(l.hooks) {
+		hook := l.hooks[l.started]
+		if hook.OnStart != nil {
+			err := hook.OnStart(ctx)
+			if err != nil {
+				startErr := fmt.Errorf("start %s: %w", hook.Name, err)
+				return errors.Join(startErr, l.stop(ctx))
+			}
+		}
+		l.started++
+	}
+
+	return nil
+}
+
+// Stop останавливает запущенные части приложения в обратном порядке.
+// Ошибка одной части не прерывает остановку остальных.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stop(ctx)
+}
+
+func (l *Lifecycle) stop(ctx context.Context) error {
+	var errs []error
+	for ; l.started > 0; l.started-- {
+		hook := l.hooks[l.started-1]
+		if hook.OnStop == nil {
+			continue
+		}
+
+		err := hook.OnStop(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", hook.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Worker оборачивает фоновую задачу, работающую до отмены контекста.
+// Остановка отменяет контекст задачи и ждет ее завершения, но не дольше ctx.
+func Worker(name string, run func(ctx context.Context)) Hook {
+	var (
+		cancel context.CancelFunc
+		done   chan struct{}
+	)
+
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			// Задача живет до Stop, а не до отмены контекста запуска
+			var workerCtx context.Context
+			workerCtx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+			done = make(chan struct{})
+
+			go func() {
+				defer close(done)
+				run(workerCtx)
+			}()
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
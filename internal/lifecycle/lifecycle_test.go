@@ -0,0 +1,103 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+)
+
+// recorder запоминает порядок вызовов хуков
+type recorder struct {
+	calls []string
+}
+
+func (r *recorder) hook(name string, startErr error) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			r.calls = append(r.calls, "start "+name)
+			return startErr
+		},
+		OnStop: func(context.Context) error {
+			r.calls = append(r.calls, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestLifecycle_Order(t *testing.T) {
+	r := &recorder{}
+
+	l := New()
+	l.Append(r.hook("storage", nil))
+	l.Append(r.hook("http", nil))
+
+	assert.Equal(t, nil, l.Start(context.Background()))
+	assert.Equal(t, nil, l.Stop(context.Background()))
+
+	assert.Equal(t, []string{"start storage", "start http", "stop http", "stop storage"}, r.calls)
+}
+
+func TestLifecycle_StartFailure(t *testing.T) {
+	r := &recorder{}
+	bindErr := errors.New("address already in use")
+
+	l := New()
+	l.Append(r.hook("storage", nil))
+	l.Append(r.hook("http", bindErr))
+	l.Append(r.hook("worker", nil))
+
+	err := l.Start(context.Background())
+
+	assert.Equal(t, true, errors.Is(err, bindErr))
+	assert.Equal(t, []string{"start storage", "start http", "stop storage"}, r.calls)
+
+	// Повторная остановка ничего не делает
+	assert.Equal(t, nil, l.Stop(context.Background()))
+	assert.Equal(t, 3, len(r.calls))
+}
+
+func TestWorker(t *testing.T) {
+	stopped := make(chan struct{})
+
+	l := New()
+	l.Append(Worker("purge", func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	}))
+
+	startCtx, cancel := context.WithCancel(context.Background())
+	assert.Equal(t, nil, l.Start(startCtx))
+
+	// Отмена контекста запуска не останавливает задачу
+	cancel()
+	select {
+	case <-stopped:
+		t.Fatal("worker stopped before Stop")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	assert.Equal(t, nil, l.Stop(context.Background()))
+	<-stopped
+}
+
+func TestWorker_StopTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	l := New()
+	l.Append(Worker("stuck", func(context.Context) {
+		<-release
+	}))
+	assert.Equal(t, nil, l.Start(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := l.Stop(ctx)
+
+	assert.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"students-crud/internal/audit"
+	"students-crud/internal/config"
+	"students-crud/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]any {
+	var entry map[string]any
+	err := json.Unmarshal(buf.Bytes(), &entry)
+	if err != nil {
+		t.Fatalf("invalid log entry %q: %v", buf.String(), err)
+	}
+	return entry
+}
+
+func TestMask(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "ivan@example.com", expected: "i***@example.com"},
+		{value: "юлия@почта.рф", expected: "ю***@почта.рф"},
+		{value: "+79990000000", expected: "+***"},
+		{value: "", expected: ""},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, Mask(testCase.value))
+	}
+}
+
+func TestLogger_Redaction(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(&config.Logging{Level: "info", Format: config.LogFormatJSON}, buf)
+
+	err := errors.New(`duplicate key: Key (email)=(ivan@example.com) already exists`)
+	log.Info("failed to create student", "email", "ivan@example.com", "error", err)
+
+	entry := decode(t, buf)
+	assert.Equal(t, "i***@example.com", entry["email"])
+	assert.Equal(t, `duplicate key: Key (email)=(i***@example.com) already exists`, entry["error"])
+
+	buf.Reset()
+	log.Info("student created", "student", models.Student{ID: 1, Name: "Иван", Email: "ivan@example.com"})
+
+	entry = decode(t, buf)
+	assert.Equal(t, map[string]any{"id": float64(1), "email": "i***@example.com", "version": float64(0)}, entry["student"])
+}
+
+func TestLogger_Context(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(&config.Logging{Level: "warn", Format: config.LogFormatJSON}, buf)
+
+	ctx := audit.WithRequestID(context.Background(), "req-1")
+
+	log.InfoContext(ctx, "skipped below level")
+	assert.Equal(t, 0, buf.Len())
+
+	log.WarnContext(ctx, "slow query")
+	entry := decode(t, buf)
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Equal(t, "WARN", entry["level"])
+}
+
+func TestParseLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug, ParseLevel("debug"))
+	assert.Equal(t, slog.LevelError, ParseLevel("ERROR"))
+	assert.Equal(t, slog.LevelInfo, ParseLevel("verbose"))
+}
+
+func TestMiddleware(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(&config.Logging{Level: "info", Format: config.LogFormatJSON}, buf)
+
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Request = ctx.Request.WithContext(audit.WithRequestID(ctx.Request.Context(), "req-2"))
+	}, Middleware(log))
+	r.GET("/students/:id", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "student not found"})
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/students/7", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	entry := decode(t, buf)
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "request", entry["msg"])
+	assert.Equal(t, "/students/:id", entry["route"])
+	assert.Equal(t, "/students/7", entry["path"])
+	assert.Equal(t, float64(404), entry["status"])
+	assert.Equal(t, "req-2", entry["request_id"])
+}
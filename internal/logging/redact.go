@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// piiKeys атрибуты с персональными данными, значения которых не попадают в лог целиком
+var piiKeys = map[string]bool{
+	"email": true,
+	"phone": true,
+}
+
+// emailPattern находит адреса почты в произвольном тексте, например в ошибках базы
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// redact маскирует персональные данные в атрибутах записи
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if piiKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, Mask(a.Value.String()))
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(RedactText(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			a.Value = slog.StringValue(RedactText(err.Error()))
+		}
+	}
+
+	return a
+}
+
+// Mask оставляет от значения первый символ, а у адреса почты еще и домен:
+// "ivan@example.com" превращается в "i***@example.com"
+func Mask(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	local, domain, isEmail := strings.Cut(value, "@")
+	if isEmail && local != "" {
+		return firstRune(local) + "***@" + domain
+	}
+
+	return firstRune(value) + "***"
+}
+
+func firstRune(s string) string {
+	_, size := utf8.DecodeRuneInString(s)
+	return s[:size]
+}
+
+// RedactText маскирует все адреса почты в тексте
+func RedactText(text string) string {
+	return emailPattern.ReplaceAllStringFunc(text, Mask)
+}
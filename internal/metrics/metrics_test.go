@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"students-crud/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics_Middleware(t *testing.T) {
+	m := New()
+
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/students/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/students/1", "/students/2", "/unknown"} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/students/:id", "404")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", unmatchedRoute, "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpDuration))
+}
+
+func TestMetrics_QueryTracer(t *testing.T) {
+	m := New()
+	tracer := m.QueryTracer()
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("connection reset")})
+
+	assert.Equal(t, 1, testutil.CollectAndCount(m.queryDuration))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.queryErrors.WithLabelValues(otherOperation)))
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := New()
+	m.RegisterPool(func() models.PoolStats {
+		return models.PoolStats{AcquiredConns: 2, IdleConns: 3, TotalConns: 5, MaxConns: 10}
+	})
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	m.Handler().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	for _, line := range []string{"db_pool_acquired_conns 2", "db_pool_total_conns 5", "db_pool_max_conns 10"} {
+		assert.Equal(t, true, strings.Contains(rec.Body.String(), line))
+	}
+}
+
+func TestMetrics_ObserveTransition(t *testing.T) {
+	m := New()
+
+	transition := models.StatusTransition{From: models.StatusEnrolled, To: models.StatusOnLeave}
+	assert.Equal(t, nil, m.ObserveTransition(context.Background(), transition))
+	assert.Equal(t, nil, m.ObserveTransition(context.Background(), transition))
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.statusTransitions.WithLabelValues(models.StatusEnrolled, models.StatusOnLeave)))
+}
@@ -0,0 +1,13 @@
+package metrics
+
+import (
+	"context"
+
+	"students-crud/internal/models"
+)
+
+// ObserveTransition считает смену статуса студента; регистрируется как status.Hook
+func (m *Metrics) ObserveTransition(_ context.Context, transition models.StatusTransition) error {
+	m.statusTransitions.WithLabelValues(transition.From, transition.To).Inc()
+	return nil
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"students-crud/internal/models"
+	"students-crud/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// otherOperation метка запросов вне операций хранилища, например миграций
+const otherOperation = "other"
+
+type queryStartKey struct{}
+
+// queryTracer измеряет запросы к базе и группирует их по операции хранилища
+type queryTracer struct {
+	m *Metrics
+}
+
+// QueryTracer возвращает трассировщик pgx для передачи в storage.New
+func (m *Metrics) QueryTracer() pgx.QueryTracer {
+	return queryTracer{m: m}
+}
+
+func (t queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, time.Now())
+}
+
+func (t queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	t.observe(ctx, data.Err)
+}
+
+func (t queryTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, time.Now())
+}
+
+func (t queryTracer) TraceBatchQuery(context.Context, *pgx.Conn, pgx.TraceBatchQueryData) {}
+
+// TraceBatchEnd учитывает пакет как один запрос: его длительность и итоговую ошибку
+func (t queryTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	t.observe(ctx, data.Err)
+}
+
+func (t queryTracer) observe(ctx context.Context, err error) {
+	op := storage.Operation(ctx)
+	if op == "" {
+		op = otherOperation
+	}
+
+	if start, ok := ctx.Value(queryStartKey{}).(time.Time); ok {
+		t.m.queryDuration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+	}
+
+	if err != nil {
+		t.m.queryErrors.WithLabelValues(op).Inc()
+	}
+}
+
+// poolCollector снимает состояние пула соединений в момент сбора метрик
+type poolCollector struct {
+	stats func() models.PoolStats
+
+	acquired     *prometheus.Desc
+	idle         *prometheus.Desc
+	total        *prometheus.Desc
+	max          *prometheus.Desc
+	acquires     *prometheus.Desc
+	emptyAcquire *prometheus.Desc
+}
+
+// RegisterPool регистрирует метрики пула соединений; stats вызывается при каждом сборе
+func (m *Metrics) RegisterPool(stats func() models.PoolStats) {
+	m.registry.MustRegister(&poolCollector{
+		stats:        stats,
+		acquired:     prometheus.NewDesc("db_pool_acquired_conns", "Number of connections currently in use.", nil, nil),
+		idle:         prometheus.NewDesc("db_pool_idle_conns", "Number of idle connections in the pool.", nil, nil),
+		total:        prometheus.NewDesc("db_pool_total_conns", "Total number of connections in the pool.", nil, nil),
+		max:          prometheus.NewDesc("db_pool_max_conns", "Maximum size of the pool.", nil, nil),
+		acquires:     prometheus.NewDesc("db_pool_acquires_total", "Number of successful connection acquires.", nil, nil),
+		emptyAcquire: prometheus.NewDesc("db_pool_empty_acquires_total", "Number of acquires that had to wait for a connection.", nil, nil),
+	})
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.total
+	ch <- c.max
+	ch <- c.acquires
+	ch <- c.emptyAcquire
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats()
+
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(stats.AcquiredConns))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(stats.MaxConns))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stats.AcquireCount))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquire, prometheus.CounterValue, float64(stats.EmptyAcquireCount))
+}
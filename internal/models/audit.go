@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Действия над студентом, попадающие в журнал аудита
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+
+	// AuditTransition смена статуса обучения
+	AuditTransition = "transition"
+	// AuditGroupMove перевод в другую группу
+	AuditGroupMove = "group_move"
+)
+
+// FieldChange значение поля до и после изменения
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditEvent запись журнала изменений студента
+type AuditEvent struct {
+	ID        int                    `json:"id"`
+	StudentID int                    `json:"student_id"`
+	Action    string                 `json:"action"`
+	Actor     string                 `json:"actor,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Changes   map[string]FieldChange `json:"changes"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// AuditLog страница журнала изменений, от новых событий к старым
+type AuditLog struct {
+	Events []AuditEvent `json:"events"`
+	Total  int          `json:"total"`
+}
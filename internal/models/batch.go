@@ -0,0 +1,47 @@
+package models
+
+// Статусы обработки элемента пакетной загрузки
+const (
+	BatchCreated    = "created"
+	BatchUpdated    = "updated"
+	BatchFailed     = "failed"
+	BatchSkipped    = "skipped"
+	BatchRolledBack = "rolled_back"
+)
+
+// BatchItemResult результат обработки одного элемента пакета
+type BatchItemResult struct {
+	Index  int    `json:"index"`
+	ID     int    `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchResult итог пакетной загрузки студентов
+type BatchResult struct {
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+	Results []BatchItemResult `json:"results"`
+}
+
+// ImportValid статус строки, прошедшей проверку при пробном импорте
+const ImportValid = "valid"
+
+// ImportRowResult результат импорта одной строки файла
+type ImportRowResult struct {
+	Row    int    `json:"row"`
+	ID     int    `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportResult отчет об импорте студентов из файла
+type ImportResult struct {
+	DryRun  bool              `json:"dry_run"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Valid   int               `json:"valid"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
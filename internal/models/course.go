@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+type Course struct {
+	ID    int    `json:"id"`
+	Code  string `json:"code" validate:"required,max=32" normalize:"trim"`
+	Title string `json:"title" validate:"required,max=255" normalize:"trim"`
+	// Term семестр, например 2024-1; семестры упорядочиваются как строки
+	Term    string `json:"term" validate:"required,max=32" normalize:"trim"`
+	Credits int    `json:"credits" validate:"min=1,max=100"`
+	// Capacity максимальное число студентов, 0 - без ограничения
+	Capacity int `json:"capacity" validate:"min=0"`
+
+	// Enrolled число записанных студентов, только для чтения
+	Enrolled int `json:"enrolled"`
+}
+
+// CourseList страница списка курсов
+type CourseList struct {
+	Courses []Course `json:"courses"`
+	Total   int      `json:"total"`
+}
+
+// Enrollment запись студента на курс. В зависимости от выборки заполнен
+// курс (курсы студента) или студент (студенты курса).
+type Enrollment struct {
+	ID         int       `json:"id"`
+	StudentID  int       `json:"student_id"`
+	CourseID   int       `json:"course_id"`
+	EnrolledAt time.Time `json:"enrolled_at"`
+	Course     *Course   `json:"course,omitempty"`
+	Student    *Student  `json:"student,omitempty"`
+}
+
+// EnrollmentList страница записей на курс
+type EnrollmentList struct {
+	Enrollments []Enrollment `json:"enrollments"`
+	Total       int          `json:"total"`
+}
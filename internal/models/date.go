@@ -0,0 +1,61 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DateLayout формат даты в JSON
+const DateLayout = "2006-01-02"
+
+// Date календарная дата без времени и часового пояса
+type Date struct {
+	time.Time
+}
+
+// NewDate создает дату из года, месяца и дня
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return fmt.Errorf("date must be a string in %s format", DateLayout)
+	}
+
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return fmt.Errorf("date must be in %s format", DateLayout)
+	}
+
+	d.Time = t
+	return nil
+}
+
+// Scan читает значение колонки DATE
+func (d *Date) Scan(src any) error {
+	t, ok := src.(time.Time)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into Date", src)
+	}
+
+	*d = NewDate(t.Date())
+	return nil
+}
+
+// Value передает дату в колонку DATE
+func (d Date) Value() (driver.Value, error) {
+	return d.Time, nil
+}
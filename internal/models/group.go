@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Group академическая группа студентов
+type Group struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name" validate:"required,max=64" normalize:"trim"`
+	Description string `json:"description" validate:"max=255" normalize:"trim"`
+
+	// Size число действующих студентов группы, только для чтения
+	Size int `json:"size"`
+}
+
+// GroupList страница списка групп
+type GroupList struct {
+	Groups []Group `json:"groups"`
+	Total  int     `json:"total"`
+}
+
+// RosterParams параметры выборки состава группы
+type RosterParams struct {
+	Limit  int
+	Offset int
+	SortBy string
+	Desc   bool
+}
+
+// GroupMove перевод студента в группу; GroupID равный nil исключает студента из группы
+type GroupMove struct {
+	GroupID *int   `json:"group_id" validate:"omitnil,min=1"`
+	Reason  string `json:"reason" validate:"max=255" normalize:"trim"`
+}
+
+// GroupMoveEvent запись истории переводов студента между группами
+type GroupMoveEvent struct {
+	ID          int       `json:"id"`
+	StudentID   int       `json:"student_id"`
+	FromGroupID *int      `json:"from_group_id"`
+	ToGroupID   *int      `json:"to_group_id"`
+	Reason      string    `json:"reason,omitempty"`
+	Actor       string    `json:"actor,omitempty"`
+	RequestID   string    `json:"request_id,omitempty"`
+	MovedAt     time.Time `json:"moved_at"`
+}
+
+// GroupMoveLog страница истории переводов, от новых к старым
+type GroupMoveLog struct {
+	Moves []GroupMoveEvent `json:"moves"`
+	Total int              `json:"total"`
+}
@@ -0,0 +1,25 @@
+package models
+
+// PoolStats состояние пула соединений с базой данных
+type PoolStats struct {
+	AcquiredConns     int32 `json:"acquired_conns"`
+	IdleConns         int32 `json:"idle_conns"`
+	TotalConns        int32 `json:"total_conns"`
+	MaxConns          int32 `json:"max_conns"`
+	AcquireCount      int64 `json:"acquire_count"`
+	EmptyAcquireCount int64 `json:"empty_acquire_count"`
+}
+
+// MigrationStatus версия схемы в базе и версия, которую ожидает приложение
+type MigrationStatus struct {
+	Version  uint `json:"version"`
+	Expected uint `json:"expected"`
+	Dirty    bool `json:"dirty"`
+}
+
+// UpToDate сообщает, что схема применена без ошибок и не старше ожидаемой.
+// Более новая схема допустима: при выкатке реплики прошлого релиза
+// продолжают работать после миграции, выполненной новым релизом
+func (m MigrationStatus) UpToDate() bool {
+	return !m.Dirty && m.Version >= m.Expected
+}
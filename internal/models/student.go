@@ -1,7 +1,154 @@
 package models
 
+import (
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Статусы обучения студента. Допустимые переходы между ними задает пакет status.
+const (
+	StatusApplicant = "applicant"
+	StatusEnrolled  = "enrolled"
+	StatusOnLeave   = "on_leave"
+	StatusGraduated = "graduated"
+	StatusExpelled  = "expelled"
+)
+
 type Student struct {
 	ID    int    `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Name  string `json:"name" validate:"required,max=255" normalize:"trim"`
+	Email string `json:"email" validate:"required,max=255,rfc5322" normalize:"trim,lower"`
+
+	// Профиль. Все поля необязательны, чтобы клиенты, знающие только name и email, продолжали работать.
+	FirstName      string `json:"first_name,omitempty" validate:"max=100" normalize:"trim"`
+	LastName       string `json:"last_name,omitempty" validate:"max=100" normalize:"trim"`
+	MiddleName     string `json:"middle_name,omitempty" validate:"max=100" normalize:"trim"`
+	BirthDate      *Date  `json:"birth_date,omitempty" validate:"omitnil,past"`
+	Phone          string `json:"phone,omitempty" validate:"phone" normalize:"trim"`
+	EnrollmentYear *int   `json:"enrollment_year,omitempty" validate:"omitnil,min=1900,max=2100"`
+
+	// Status начальный статус при создании, пустой означает enrolled.
+	// Дальше меняется только переходами, при обновлении игнорируется.
+	Status string `json:"status,omitempty" validate:"omitempty,oneof=applicant enrolled on_leave graduated expelled" normalize:"trim,lower"`
+
+	// StudentNumber номер студенческого билета вида 2024-000042; если не задан, выдается автоматически
+	StudentNumber string `json:"student_number,omitempty" validate:"student_number" normalize:"trim"`
+
+	// GroupID академическая группа студента, nil - без группы.
+	// Меняется только переводом в другую группу, в теле запросов игнорируется.
+	GroupID *int `json:"group_id,omitempty"`
+
+	// Version увеличивается при каждом изменении записи и передается клиенту через ETag
+	Version int `json:"-"`
+
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	// DeletedAt время мягкого удаления, nil для действующих записей
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// FillName составляет полное имя из фамилии, имени и отчества, если name не передан
+func (s *Student) FillName() {
+	if s.Name != "" {
+		return
+	}
+
+	var parts []string
+	for _, part := range []string{s.LastName, s.FirstName, s.MiddleName} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	s.Name = strings.Join(parts, " ")
+}
+
+// LogValue оставляет в логах только идентификатор и адрес почты, который логгер маскирует.
+// Имя в логи не попадает.
+func (s Student) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("id", s.ID),
+		slog.String("email", s.Email),
+		slog.Int("version", s.Version),
+	)
+}
+
+// Поля, по которым допускается сортировка списка студентов
+const (
+	SortByID    = "id"
+	SortByName  = "name"
+	SortByEmail = "email"
+)
+
+// ListParams описывает параметры выборки списка студентов
+type ListParams struct {
+	Limit       int
+	Offset      int
+	Cursor      string
+	Name        string
+	EmailDomain string
+	SortBy      string
+	Desc        bool
+	WithDeleted bool
+}
+
+// StudentList страница списка студентов
+type StudentList struct {
+	Students   []Student `json:"students"`
+	Total      int       `json:"total"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// StudentPatch частичное обновление студента, поля со значением nil не изменяются.
+// Пустая строка очищает необязательное строковое поле.
+type StudentPatch struct {
+	Name           *string `json:"name,omitempty" validate:"omitnil,required,max=255" normalize:"trim"`
+	Email          *string `json:"email,omitempty" validate:"omitnil,required,max=255,rfc5322" normalize:"trim,lower"`
+	FirstName      *string `json:"first_name,omitempty" validate:"omitnil,max=100" normalize:"trim"`
+	LastName       *string `json:"last_name,omitempty" validate:"omitnil,max=100" normalize:"trim"`
+	MiddleName     *string `json:"middle_name,omitempty" validate:"omitnil,max=100" normalize:"trim"`
+	BirthDate      *Date   `json:"birth_date,omitempty" validate:"omitnil,past"`
+	Phone          *string `json:"phone,omitempty" validate:"omitnil,phone" normalize:"trim"`
+	EnrollmentYear *int    `json:"enrollment_year,omitempty" validate:"omitnil,min=1900,max=2100"`
+
+	// Clear необязательные поля без строкового значения, которые нужно очистить: birth_date, enrollment_year
+	Clear []string `json:"-"`
+
+	// Version ожидаемая версия записи, 0 - без проверки
+	Version int `json:"-"`
+}
+
+// Поля, которые можно очистить через StudentPatch.Clear
+const (
+	FieldBirthDate      = "birth_date"
+	FieldEnrollmentYear = "enrollment_year"
+)
+
+// Empty сообщает, что патч не изменяет ни одного поля
+func (p StudentPatch) Empty() bool {
+	return p.Name == nil && p.Email == nil && p.FirstName == nil && p.LastName == nil && p.MiddleName == nil &&
+		p.BirthDate == nil && p.Phone == nil && p.EnrollmentYear == nil && len(p.Clear) == 0
+}
+
+// SearchParams параметры поиска студентов
+type SearchParams struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// SearchResult найденный студент с релевантностью и подсвеченными совпадениями.
+// Highlights содержит только поля с совпадениями; значения экранированы для HTML,
+// совпавшие фрагменты обрамлены тегом <mark>.
+type SearchResult struct {
+	Student
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+// SearchList страница результатов поиска, от наиболее релевантных
+type SearchList struct {
+	Results []SearchResult `json:"results"`
+	Total   int            `json:"total"`
 }
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// TransitionInput запрос на смену статуса обучения студента
+type TransitionInput struct {
+	To     string `json:"to" validate:"required,oneof=applicant enrolled on_leave graduated expelled" normalize:"trim,lower"`
+	Reason string `json:"reason" validate:"required,max=500" normalize:"trim"`
+}
+
+// StatusTransition запись истории смены статуса студента
+type StatusTransition struct {
+	ID        int       `json:"id"`
+	StudentID int       `json:"student_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Reason    string    `json:"reason"`
+	Actor     string    `json:"actor,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StatusHistory страница истории смены статуса, от новых к старым
+type StatusHistory struct {
+	Transitions []StatusTransition `json:"transitions"`
+	Total       int                `json:"total"`
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"errors"
+
+	"students-crud/internal/status"
+	"students-crud/internal/storage"
+)
+
+// Доменные ошибки сервиса. Ошибки хранилища и статусов, значимые для клиентов,
+// доступны под теми же значениями, чтобы вызывающему коду не нужно было зависеть от storage.
+var (
+	ErrNotFound          = storage.ErrNotFound
+	ErrVersionConflict   = storage.ErrVersionConflict
+	ErrInvalidCursor     = storage.ErrInvalidCursor
+	ErrEmailTaken        = storage.ErrEmailTaken
+	ErrNumberTaken       = storage.ErrNumberTaken
+	ErrAlreadyExists     = storage.ErrAlreadyExists
+	ErrMissingField      = storage.ErrMissingField
+	ErrValueTooLong      = storage.ErrValueTooLong
+	ErrInvalidValue      = storage.ErrInvalidValue
+	ErrIllegalTransition = status.ErrIllegalTransition
+	ErrUnknownStatus     = status.ErrUnknownStatus
+
+	ErrCourseNotFound     = storage.ErrCourseNotFound
+	ErrCourseExists       = storage.ErrCourseExists
+	ErrCourseInUse        = storage.ErrCourseInUse
+	ErrCourseFull         = storage.ErrCourseFull
+	ErrEnrollmentNotFound = storage.ErrEnrollmentNotFound
+	ErrAlreadyEnrolled    = storage.ErrAlreadyEnrolled
+
+	ErrGroupNotFound = storage.ErrGroupNotFound
+	ErrGroupExists   = storage.ErrGroupExists
+	ErrGroupNotEmpty = storage.ErrGroupNotEmpty
+
+	ErrEmptyQuery   = errors.New("missing query")
+	ErrQueryTooLong = errors.New("query too long")
+)
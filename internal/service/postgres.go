@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+
+	"students-crud/internal/storage"
+)
+
+// FromStorage приспосабливает хранилище Postgres к интерфейсу Storage
+func FromStorage(s *storage.Storage) Storage {
+	return pgStorage{s}
+}
+
+// pgStorage передает в WithTx хранилище транзакции в виде Storage
+type pgStorage struct {
+	*storage.Storage
+}
+
+func (s pgStorage) WithTx(ctx context.Context, fn func(tx Storage) error) error {
+	return s.Storage.WithTx(ctx, func(tx *storage.Storage) error {
+		return fn(pgStorage{tx})
+	})
+}
@@ -0,0 +1,383 @@
+// Package service содержит бизнес-правила работы со студентами: нормализацию и проверку данных,
+// допустимые смены статуса и побочные действия после них. HTTP-обработчики, CLI и другие
+// фронтенды вызывают сервис и не обращаются к хранилищу напрямую.
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"students-crud/internal/models"
+	"students-crud/internal/status"
+	"students-crud/internal/validation"
+)
+
+// maxSearchQueryLength ограничивает длину поисковой строки
+const maxSearchQueryLength = 255
+
+//go:generate mockgen -source=service.go -destination=mock/storage.go
+type Storage interface {
+	Create(ctx context.Context, student *models.Student) (int, error)
+	Read(ctx context.Context, id int) (*models.Student, error)
+	ReadForUpdate(ctx context.Context, id int) (*models.Student, error)
+	List(ctx context.Context, params models.ListParams) (*models.StudentList, error)
+	Search(ctx context.Context, params models.SearchParams) (*models.SearchList, error)
+	Update(ctx context.Context, student *models.Student) error
+	Patch(ctx context.Context, id int, patch models.StudentPatch) (*models.Student, error)
+	Delete(ctx context.Context, id int, version int) error
+	Restore(ctx context.Context, id int) (*models.Student, error)
+	Purge(ctx context.Context, id int) error
+	History(ctx context.Context, studentID, limit, offset int) (*models.AuditLog, error)
+	Upsert(ctx context.Context, students []models.Student, atomic bool) ([]models.BatchItemResult, error)
+	Export(ctx context.Context, fn func(student models.Student) error) error
+	Transition(ctx context.Context, studentID int, input models.TransitionInput) (*models.StatusTransition, error)
+	StatusHistory(ctx context.Context, studentID, limit, offset int) (*models.StatusHistory, error)
+
+	// Курсы и записи на них
+	Enroll(ctx context.Context, studentID, courseID int) (*models.Enrollment, error)
+	Unenroll(ctx context.Context, studentID, courseID int) error
+	StudentCourses(ctx context.Context, studentID int) ([]models.Enrollment, error)
+
+	// Группы
+	MoveStudent(ctx context.Context, studentID int, move models.GroupMove) (*models.Student, error)
+	GroupHistory(ctx context.Context, studentID, limit, offset int) (*models.GroupMoveLog, error)
+
+	// WithTx выполняет fn в транзакции; вызовы tx внутри fn фиксируются вместе.
+	// fn может быть вызвана повторно при конфликте сериализации.
+	WithTx(ctx context.Context, fn func(tx Storage) error) error
+}
+
+// StudentService бизнес-правила работы со студентами поверх хранилища
+type StudentService struct {
+	storage Storage
+	hooks   *status.Hooks
+	log     *slog.Logger
+}
+
+// NewStudentService создает сервис студентов; hooks вызываются после каждой смены статуса
+func NewStudentService(storage Storage, hooks *status.Hooks, log *slog.Logger) *StudentService {
+	return &StudentService{storage: storage, hooks: hooks, log: log}
+}
+
+// Prepare нормализует студента, составляет name из ФИО, если он не задан, и проверяет поля.
+// Возвращает validation.Errors со всеми нарушенными правилами.
+func Prepare(student *models.Student) error {
+	validation.Normalize(student)
+	student.FillName()
+
+	return validation.Validate(student)
+}
+
+// Create проверяет и создает студента. Задать при создании можно только начальный статус.
+func (s *StudentService) Create(ctx context.Context, student *models.Student) (int, error) {
+	err := prepareNew(student)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.storage.Create(ctx, student)
+}
+
+// prepareNew проверяет нового студента, включая начальный статус
+func prepareNew(student *models.Student) error {
+	err := Prepare(student)
+	if err != nil {
+		return err
+	}
+
+	if student.Status != "" {
+		return status.CheckInitial(student.Status)
+	}
+
+	return nil
+}
+
+// Read возвращает действующего студента по ID
+func (s *StudentService) Read(ctx context.Context, id int) (*models.Student, error) {
+	return s.storage.Read(ctx, id)
+}
+
+// List возвращает страницу списка студентов
+func (s *StudentService) List(ctx context.Context, params models.ListParams) (*models.StudentList, error) {
+	return s.storage.List(ctx, params)
+}
+
+// Search ищет студентов по непустой строке не длиннее maxSearchQueryLength символов
+func (s *StudentService) Search(ctx context.Context, params models.SearchParams) (*models.SearchList, error) {
+	params.Query = strings.TrimSpace(params.Query)
+	if params.Query == "" {
+		return nil, ErrEmptyQuery
+	}
+	if utf8.RuneCountInString(params.Query) > maxSearchQueryLength {
+		return nil, ErrQueryTooLong
+	}
+
+	return s.storage.Search(ctx, params)
+}
+
+// Update проверяет и обновляет студента. Статус и группа при этом не меняются.
+// Незаданные поля профиля сохраняют прежние значения, чтобы клиенты, знающие только
+// name и email, не стирали профиль; очистить поле можно частичным обновлением.
+func (s *StudentService) Update(ctx context.Context, student *models.Student) error {
+	var updated models.Student
+	err := s.storage.WithTx(ctx, func(tx Storage) error {
+		// Транзакция может повториться, поэтому каждая попытка начинает с исходных данных:
+		// Update записывает в студента новую версию, и повтор не прошел бы проверку версии
+		updated = *student
+
+		// Строка блокируется до конца транзакции, чтобы параллельное изменение не было
+		// перезаписано прежними значениями профиля
+		current, err := tx.ReadForUpdate(ctx, updated.ID)
+		if err != nil {
+			return err
+		}
+
+		keepProfile(&updated, current)
+
+		err = Prepare(&updated)
+		if err != nil {
+			return err
+		}
+
+		return tx.Update(ctx, &updated)
+	})
+	if err != nil {
+		return err
+	}
+
+	*student = updated
+	return nil
+}
+
+// keepProfile переносит в student поля профиля current, которых нет в запросе
+func keepProfile(student, current *models.Student) {
+	for _, field := range []struct {
+		value   *string
+		current string
+	}{
+		{&student.FirstName, current.FirstName},
+		{&student.LastName, current.LastName},
+		{&student.MiddleName, current.MiddleName},
+		{&student.Phone, current.Phone},
+		{&student.StudentNumber, current.StudentNumber},
+	} {
+		if *field.value == "" {
+			*field.value = field.current
+		}
+	}
+
+	if student.BirthDate == nil {
+		student.BirthDate = current.BirthDate
+	}
+	if student.EnrollmentYear == nil {
+		student.EnrollmentYear = current.EnrollmentYear
+	}
+}
+
+// Patch проверяет и применяет частичное обновление
+func (s *StudentService) Patch(ctx context.Context, id int, patch models.StudentPatch) (*models.Student, error) {
+	err := validation.Validate(&patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.storage.Patch(ctx, id, patch)
+}
+
+// ApplyPatch строит частичное обновление по текущему состоянию студента и применяет его
+// в одной транзакции. Запись не должна измениться между чтением и сохранением: иначе
+// возвращается ErrVersionConflict. version - ожидаемая клиентом версия, 0 - без проверки.
+func (s *StudentService) ApplyPatch(ctx context.Context, id, version int, build func(current *models.Student) (models.StudentPatch, error)) (*models.Student, error) {
+	var student *models.Student
+	err := s.storage.WithTx(ctx, func(tx Storage) error {
+		current, err := tx.Read(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if version != 0 && version != current.Version {
+			return fmt.Errorf("service.ApplyPatch: %w", ErrVersionConflict)
+		}
+
+		patch, err := build(current)
+		if err != nil {
+			return err
+		}
+		patch.Version = current.Version
+
+		err = validation.Validate(&patch)
+		if err != nil {
+			return err
+		}
+
+		student, err = tx.Patch(ctx, id, patch)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return student, nil
+}
+
+// Delete мягко удаляет студента; version - ожидаемая версия, 0 - без проверки
+func (s *StudentService) Delete(ctx context.Context, id int, version int) error {
+	return s.storage.Delete(ctx, id, version)
+}
+
+// Restore восстанавливает мягко удаленного студента
+func (s *StudentService) Restore(ctx context.Context, id int) (*models.Student, error) {
+	return s.storage.Restore(ctx, id)
+}
+
+// Purge безвозвратно удаляет студента
+func (s *StudentService) Purge(ctx context.Context, id int) error {
+	return s.storage.Purge(ctx, id)
+}
+
+// History возвращает журнал изменений студента
+func (s *StudentService) History(ctx context.Context, studentID, limit, offset int) (*models.AuditLog, error) {
+	return s.storage.History(ctx, studentID, limit, offset)
+}
+
+// Upsert проверяет студентов и сохраняет валидных. Невалидные отмечаются в результатах как failed;
+// при atomic=true любой невалидный студент отменяет пакет до обращения к хранилищу, а валидные
+// отмечаются как rolled_back, как и при откате пакета в хранилище.
+// Результаты возвращаются в том же порядке, что и students.
+func (s *StudentService) Upsert(ctx context.Context, students []models.Student, atomic bool) ([]models.BatchItemResult, error) {
+	results, indices := prepareBatch(students)
+	if atomic && len(indices) < len(students) {
+		for _, i := range indices {
+			results[i].Status = models.BatchRolledBack
+		}
+		return results, nil
+	}
+
+	valid := make([]models.Student, 0, len(indices))
+	for _, i := range indices {
+		valid = append(valid, students[i])
+	}
+
+	stored, err := s.storage.Upsert(ctx, valid, atomic)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, result := range stored {
+		result.Index = indices[j]
+		results[indices[j]] = result
+	}
+
+	return results, nil
+}
+
+// ValidateImport проверяет студентов, как Upsert, но ничего не сохраняет: результат
+// пробного импорта. Валидные студенты отмечаются как valid, невалидные - как failed.
+func (s *StudentService) ValidateImport(students []models.Student) []models.BatchItemResult {
+	results, indices := prepareBatch(students)
+	for _, i := range indices {
+		results[i].Status = models.ImportValid
+	}
+
+	return results
+}
+
+// prepareBatch нормализует и проверяет студентов пакета. Возвращает результаты, где
+// невалидные уже отмечены как failed, а остальные как skipped, и индексы валидных.
+func prepareBatch(students []models.Student) ([]models.BatchItemResult, []int) {
+	results := make([]models.BatchItemResult, len(students))
+	indices := make([]int, 0, len(students))
+
+	for i := range students {
+		err := Prepare(&students[i])
+		if err != nil {
+			results[i] = models.BatchItemResult{Index: i, Status: models.BatchFailed, Error: err.Error()}
+			continue
+		}
+
+		results[i] = models.BatchItemResult{Index: i, Status: models.BatchSkipped}
+		indices = append(indices, i)
+	}
+
+	return results, indices
+}
+
+// Export передает fn всех действующих студентов по одному
+func (s *StudentService) Export(ctx context.Context, fn func(student models.Student) error) error {
+	return s.storage.Export(ctx, fn)
+}
+
+// Transition меняет статус студента, если переход допустим из текущего статуса, и после
+// фиксации перехода вызывает побочные действия. Текущий статус читается с блокировкой строки,
+// чтобы параллельный переход не изменил его до записи.
+// Сбой побочных действий не отменяет переход и только записывается в лог.
+func (s *StudentService) Transition(ctx context.Context, studentID int, input models.TransitionInput) (*models.StatusTransition, error) {
+	err := validation.Validate(&input)
+	if err != nil {
+		return nil, err
+	}
+
+	var transition *models.StatusTransition
+	err = s.storage.WithTx(ctx, func(tx Storage) error {
+		current, err := tx.ReadForUpdate(ctx, studentID)
+		if err != nil {
+			return err
+		}
+
+		err = status.Check(current.Status, input.To)
+		if err != nil {
+			return err
+		}
+
+		transition, err = tx.Transition(ctx, studentID, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.hooks.Run(ctx, *transition)
+	if err != nil {
+		s.log.WarnContext(ctx, "status transition hooks failed", "error", err)
+	}
+
+	return transition, nil
+}
+
+// StatusHistory возвращает историю смены статуса студента
+func (s *StudentService) StatusHistory(ctx context.Context, studentID, limit, offset int) (*models.StatusHistory, error) {
+	return s.storage.StatusHistory(ctx, studentID, limit, offset)
+}
+
+// Enroll записывает студента на курс
+func (s *StudentService) Enroll(ctx context.Context, studentID, courseID int) (*models.Enrollment, error) {
+	return s.storage.Enroll(ctx, studentID, courseID)
+}
+
+// Unenroll отписывает студента от курса
+func (s *StudentService) Unenroll(ctx context.Context, studentID, courseID int) error {
+	return s.storage.Unenroll(ctx, studentID, courseID)
+}
+
+// StudentCourses возвращает записи студента на курсы
+func (s *StudentService) StudentCourses(ctx context.Context, studentID int) ([]models.Enrollment, error) {
+	return s.storage.StudentCourses(ctx, studentID)
+}
+
+// MoveStudent проверяет перевод и переводит студента в группу или исключает из группы
+func (s *StudentService) MoveStudent(ctx context.Context, studentID int, move models.GroupMove) (*models.Student, error) {
+	err := validation.Validate(&move)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.storage.MoveStudent(ctx, studentID, move)
+}
+
+// GroupHistory возвращает историю переводов студента между группами
+func (s *StudentService) GroupHistory(ctx context.Context, studentID, limit, offset int) (*models.GroupMoveLog, error) {
+	return s.storage.GroupHistory(ctx, studentID, limit, offset)
+}
@@ -0,0 +1,445 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"students-crud/internal/models"
+	"students-crud/internal/service"
+	mock_service "students-crud/internal/service/mock"
+	"students-crud/internal/status"
+	"students-crud/internal/validation"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestStudentService_Create(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockStorage)
+
+	birthDate := models.NewDate(2004, time.May, 17)
+
+	testCases := []struct {
+		name          string
+		input         models.Student
+		mockBehaviour mockBehavior
+		expectedID    int
+		expectedErr   error
+	}{
+		{
+			name:  "Normalized Profile",
+			input: models.Student{FirstName: " Ivan", LastName: "Petrov ", Email: " IVAN@mail.com", BirthDate: &birthDate},
+			mockBehaviour: func(s *mock_service.MockStorage) {
+				s.EXPECT().Create(gomock.Any(), &models.Student{
+					Name: "Petrov Ivan", FirstName: "Ivan", LastName: "Petrov", Email: "ivan@mail.com", BirthDate: &birthDate,
+				}).Return(1, nil)
+			},
+			expectedID: 1,
+		},
+		{
+			name:  "Applicant",
+			input: models.Student{Name: "Student #2", Email: "#2@mail.com", Status: models.StatusApplicant},
+			mockBehaviour: func(s *mock_service.MockStorage) {
+				s.EXPECT().Create(gomock.Any(), gomock.Any()).Return(2, nil)
+			},
+			expectedID: 2,
+		},
+		{
+			name:          "Graduated",
+			input:         models.Student{Name: "Student #3", Email: "#3@mail.com", Status: models.StatusGraduated},
+			mockBehaviour: func(s *mock_service.MockStorage) {},
+			expectedErr:   service.ErrIllegalTransition,
+		},
+		{
+			name:          "Invalid",
+			input:         models.Student{Email: "#4@mail.com", Phone: "8 999 123"},
+			mockBehaviour: func(s *mock_service.MockStorage) {},
+			expectedErr: validation.Errors{
+				{Field: "name", Rule: "required", Message: "is required"},
+				{Field: "phone", Rule: "phone", Message: "must be a phone number in E.164 format, e.g. +79991234567"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			storage := mock_service.NewMockStorage(c)
+			testCase.mockBehaviour(storage)
+
+			students := service.NewStudentService(storage, status.NewHooks(), testLogger)
+
+			id, err := students.Create(context.Background(), &testCase.input)
+
+			assert.Equal(t, testCase.expectedID, id)
+			var verrs validation.Errors
+			if errors.As(err, &verrs) {
+				assert.Equal(t, testCase.expectedErr, verrs)
+			} else {
+				assert.Equal(t, true, errors.Is(err, testCase.expectedErr))
+			}
+		})
+	}
+}
+
+func TestStudentService_Search(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	storage := mock_service.NewMockStorage(c)
+	storage.EXPECT().Search(gomock.Any(), models.SearchParams{Query: "ivan", Limit: 5}).Return(&models.SearchList{Total: 1}, nil)
+
+	students := service.NewStudentService(storage, status.NewHooks(), testLogger)
+
+	list, err := students.Search(context.Background(), models.SearchParams{Query: " ivan ", Limit: 5})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, list.Total)
+
+	_, err = students.Search(context.Background(), models.SearchParams{Query: "  "})
+	assert.Equal(t, service.ErrEmptyQuery, err)
+
+	_, err = students.Search(context.Background(), models.SearchParams{Query: strings.Repeat("я", 256)})
+	assert.Equal(t, service.ErrQueryTooLong, err)
+}
+
+func TestStudentService_Update(t *testing.T) {
+	birthDate := models.NewDate(2004, time.May, 17)
+	year := 2022
+	current := &models.Student{
+		ID: 1, Name: "Petrov Ivan", Email: "ivan@mail.com", FirstName: "Ivan", LastName: "Petrov",
+		BirthDate: &birthDate, Phone: "+79991234567", EnrollmentYear: &year, StudentNumber: "2022-000001",
+		Status: models.StatusEnrolled, Version: 2,
+	}
+
+	testCases := []struct {
+		name     string
+		input    models.Student
+		expected models.Student
+	}{
+		{
+			name:  "Legacy Client Keeps Profile",
+			input: models.Student{ID: 1, Name: " Ivan Petrov ", Email: "IVAN@mail.com", Version: 2},
+			expected: models.Student{
+				ID: 1, Name: "Ivan Petrov", Email: "ivan@mail.com", FirstName: "Ivan", LastName: "Petrov",
+				BirthDate: &birthDate, Phone: "+79991234567", EnrollmentYear: &year, StudentNumber: "2022-000001", Version: 2,
+			},
+		},
+		{
+			name:  "Profile Replaced",
+			input: models.Student{ID: 1, Name: "Ivan Petrov", Email: "ivan@mail.com", FirstName: "Ivan", LastName: "Petrov", Phone: "+79990000000"},
+			expected: models.Student{
+				ID: 1, Name: "Ivan Petrov", Email: "ivan@mail.com", FirstName: "Ivan", LastName: "Petrov",
+				BirthDate: &birthDate, Phone: "+79990000000", EnrollmentYear: &year, StudentNumber: "2022-000001",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			storage := mock_service.NewMockStorage(c)
+			expectTx(storage)
+			storage.EXPECT().ReadForUpdate(gomock.Any(), 1).Return(current, nil)
+			storage.EXPECT().Update(gomock.Any(), &testCase.expected).Return(nil)
+
+			students := service.NewStudentService(storage, status.NewHooks(), testLogger)
+
+			student := testCase.input
+			err := students.Update(context.Background(), &student)
+			assert.Equal(t, nil, err)
+		})
+	}
+}
+
+func TestStudentService_Update_LockedRow(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	inTx := false
+	storage := mock_service.NewMockStorage(c)
+	storage.EXPECT().WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(tx service.Storage) error) error {
+			inTx = true
+			defer func() { inTx = false }()
+			return fn(storage)
+		})
+	// Профиль берется из строки, прочитанной с блокировкой внутри транзакции:
+	// телефон изменен параллельным запросом, зафиксированным до блокировки
+	storage.EXPECT().ReadForUpdate(gomock.Any(), 1).
+		DoAndReturn(func(_ context.Context, id int) (*models.Student, error) {
+			assert.Equal(t, true, inTx)
+			return &models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", Phone: "+79990000000", Version: 3}, nil
+		})
+	storage.EXPECT().Update(gomock.Any(), &models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", Phone: "+79990000000"}).
+		DoAndReturn(func(_ context.Context, student *models.Student) error {
+			assert.Equal(t, true, inTx)
+			return nil
+		})
+
+	students := service.NewStudentService(storage, status.NewHooks(), testLogger)
+
+	student := models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com"}
+	err := students.Update(context.Background(), &student)
+	assert.Equal(t, nil, err)
+}
+
+func TestStudentService_Update_Retry(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	current := &models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", Version: 2}
+
+	storage := mock_service.NewMockStorage(c)
+	// Первая попытка обновляет строку, но фиксация прерывается конфликтом сериализации
+	storage.EXPECT().WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(tx service.Storage) error) error {
+			_ = fn(storage)
+			return fn(storage)
+		})
+	storage.EXPECT().ReadForUpdate(gomock.Any(), 1).Return(current, nil).Times(2)
+	storage.EXPECT().Update(gomock.Any(), &models.Student{ID: 1, Name: "Student #2", Email: "#2@mail.com", Version: 2}).
+		DoAndReturn(func(_ context.Context, student *models.Student) error {
+			student.Version++
+			return nil
+		}).Times(2)
+
+	students := service.NewStudentService(storage, status.NewHooks(), testLogger)
+
+	student := models.Student{ID: 1, Name: "Student #2", Email: "#2@mail.com", Version: 2}
+	err := students.Update(context.Background(), &student)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, student.Version)
+}
+
+// expectTx ожидает транзакцию, в которой вызовы идут в то же хранилище
+func expectTx(storage *mock_service.MockStorage) {
+	storage.EXPECT().WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(tx service.Storage) error) error {
+			return fn(storage)
+		})
+}
+
+func TestStudentService_ApplyPatch(t *testing.T) {
+	name := "Patched Student"
+	current := &models.Student{ID: 1, Name: "Student #1", Email: "#1@mail.com", Version: 3}
+	build := func(student *models.Student) (models.StudentPatch, error) {
+		assert.Equal(t, current, student)
+		return models.StudentPatch{Name: &name}, nil
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		storage := mock_service.NewMockStorage(c)
+		expectTx(storage)
+		storage.EXPECT().Read(gomock.Any(), 1).Return(current, nil)
+		storage.EXPECT().Patch(gomock.Any(), 1, models.StudentPatch{Name: &name, Version: 3}).
+			Return(&models.Student{ID: 1, Name: name, Email: current.Email, Version: 4}, nil)
+
+		students := service.NewStudentService(storage, status.NewHooks(), testLogger)
+
+		student, err := students.ApplyPatch(context.Background(), 1, 3, build)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 4, student.Version)
+	})
+
+	t.Run("Version Conflict", func(t *testing.T) {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		storage := mock_service.NewMockStorage(c)
+		expectTx(storage)
+		storage.EXPECT().Read(gomock.Any(), 1).Return(current, nil)
+
+		students := service.NewStudentService(storage, status.NewHooks(), testLogger)
+
+		_, err := students.ApplyPatch(context.Background(), 1, 2, build)
+		assert.Equal(t, true, errors.Is(err, service.ErrVersionConflict))
+	})
+
+	t.Run("Invalid Patch", func(t *testing.T) {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		storage := mock_service.NewMockStorage(c)
+		expectTx(storage)
+		storage.EXPECT().Read(gomock.Any(), 1).Return(current, nil)
+
+		students := service.NewStudentService(storage, status.NewHooks(), testLogger)
+
+		_, err := students.ApplyPatch(context.Background(), 1, 0, func(*models.Student) (models.StudentPatch, error) {
+			email := "not-an-email"
+			return models.StudentPatch{Email: &email}, nil
+		})
+		var verrs validation.Errors
+		assert.Equal(t, true, errors.As(err, &verrs))
+	})
+}
+
+func TestStudentService_Upsert(t *testing.T) {
+	input := func() []models.Student {
+		return []models.Student{
+			{Name: " Student #1 ", Email: "#1@mail.com"},
+			{Name: "Student #2", Email: "not-an-email"},
+			{Name: "Student #3", Email: "#3@mail.com"},
+		}
+	}
+
+	t.Run("Partial", func(t *testing.T) {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		storage := mock_service.NewMockStorage(c)
+		storage.EXPECT().Upsert(gomock.Any(), []models.Student{
+			{Name: "Student #1", Email: "#1@mail.com"},
+			{Name: "Student #3", Email: "#3@mail.com"},
+		}, false).Return([]models.BatchItemResult{
+			{Index: 0, ID: 1, Status: models.BatchCreated},
+			{Index: 1, ID: 3, Status: models.BatchUpdated},
+		}, nil)
+
+		students := service.NewStudentService(storage, status.NewHooks(), testLogger)
+
+		results, err := students.Upsert(context.Background(), input(), false)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []models.BatchItemResult{
+			{Index: 0, ID: 1, Status: models.BatchCreated},
+			{Index: 1, Status: models.BatchFailed, Error: "validation failed: email must be a valid email address"},
+			{Index: 2, ID: 3, Status: models.BatchUpdated},
+		}, results)
+	})
+
+	t.Run("Atomic", func(t *testing.T) {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		students := service.NewStudentService(mock_service.NewMockStorage(c), status.NewHooks(), testLogger)
+
+		results, err := students.Upsert(context.Background(), input(), true)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, models.BatchFailed, results[1].Status)
+		assert.Equal(t, models.BatchRolledBack, results[0].Status)
+	})
+}
+
+func TestStudentService_ValidateImport(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	students := service.NewStudentService(mock_service.NewMockStorage(c), status.NewHooks(), testLogger)
+
+	input := []models.Student{
+		{Name: " Student #1 ", Email: "#1@MAIL.com"},
+		{Email: "#2@mail.com"},
+	}
+
+	results := students.ValidateImport(input)
+	assert.Equal(t, []models.BatchItemResult{
+		{Index: 0, Status: models.ImportValid},
+		{Index: 1, Status: models.BatchFailed, Error: "validation failed: name is required"},
+	}, results)
+	assert.Equal(t, models.Student{Name: "Student #1", Email: "#1@mail.com"}, input[0])
+}
+
+func TestStudentService_Transition(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	input := models.TransitionInput{To: models.StatusOnLeave, Reason: "medical leave"}
+	transition := &models.StatusTransition{ID: 1, StudentID: 7, From: models.StatusEnrolled, To: models.StatusOnLeave}
+
+	storage := mock_service.NewMockStorage(c)
+	expectTx(storage)
+	gomock.InOrder(
+		storage.EXPECT().ReadForUpdate(gomock.Any(), 7).Return(&models.Student{ID: 7, Status: models.StatusEnrolled}, nil),
+		storage.EXPECT().Transition(gomock.Any(), 7, input).Return(transition, nil),
+	)
+
+	var calls []string
+	hooks := status.NewHooks()
+	hooks.Register("failing", func(ctx context.Context, transition models.StatusTransition) error {
+		calls = append(calls, "failing")
+		return errors.New("notify failed")
+	})
+	hooks.Register("audit", func(ctx context.Context, transition models.StatusTransition) error {
+		calls = append(calls, "audit:"+transition.To)
+		return nil
+	})
+
+	students := service.NewStudentService(storage, hooks, testLogger)
+
+	result, err := students.Transition(context.Background(), 7, models.TransitionInput{To: " ON_LEAVE ", Reason: " medical leave "})
+
+	// Сбой побочного действия не отменяет зафиксированный переход
+	assert.Equal(t, nil, err)
+	assert.Equal(t, transition, result)
+	assert.Equal(t, []string{"failing", "audit:on_leave"}, calls)
+}
+
+func TestStudentService_Transition_Illegal(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	storage := mock_service.NewMockStorage(c)
+	expectTx(storage)
+	storage.EXPECT().ReadForUpdate(gomock.Any(), 7).Return(&models.Student{ID: 7, Status: models.StatusGraduated}, nil)
+
+	called := false
+	hooks := status.NewHooks()
+	hooks.Register("audit", func(ctx context.Context, transition models.StatusTransition) error {
+		called = true
+		return nil
+	})
+
+	students := service.NewStudentService(storage, hooks, testLogger)
+
+	// Недопустимый переход отклоняется до записи, побочные действия не вызываются
+	_, err := students.Transition(context.Background(), 7, models.TransitionInput{To: models.StatusEnrolled, Reason: "readmission"})
+	assert.Equal(t, true, errors.Is(err, service.ErrIllegalTransition))
+	assert.Equal(t, false, called)
+}
+
+func TestStudentService_MoveStudent(t *testing.T) {
+	groupID := 3
+
+	t.Run("Normalized", func(t *testing.T) {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		storage := mock_service.NewMockStorage(c)
+		storage.EXPECT().MoveStudent(gomock.Any(), 1, models.GroupMove{GroupID: &groupID, Reason: "transfer"}).
+			Return(&models.Student{ID: 1, GroupID: &groupID, Version: 2}, nil)
+
+		students := service.NewStudentService(storage, status.NewHooks(), testLogger)
+
+		student, err := students.MoveStudent(context.Background(), 1, models.GroupMove{GroupID: &groupID, Reason: " transfer "})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 2, student.Version)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		students := service.NewStudentService(mock_service.NewMockStorage(c), status.NewHooks(), testLogger)
+
+		invalidID := 0
+		_, err := students.MoveStudent(context.Background(), 1, models.GroupMove{GroupID: &invalidID})
+
+		var verrs validation.Errors
+		assert.Equal(t, true, errors.As(err, &verrs))
+		assert.Equal(t, validation.Errors{{Field: "group_id", Rule: "min", Message: "must be at least 1"}}, verrs)
+	})
+}
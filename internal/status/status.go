@@ -0,0 +1,105 @@
+// Package status задает допустимые переходы между статусами обучения студента
+// и вызывает обработчики, подписанные на смену статуса
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"students-crud/internal/models"
+)
+
+var (
+	ErrUnknownStatus     = errors.New("unknown status")
+	ErrIllegalTransition = errors.New("illegal status transition")
+)
+
+// transitions статусы, в которые можно перейти из данного.
+// graduated и expelled конечные: из них переходов нет.
+var transitions = map[string][]string{
+	models.StatusApplicant: {models.StatusEnrolled},
+	models.StatusEnrolled:  {models.StatusOnLeave, models.StatusGraduated, models.StatusExpelled},
+	models.StatusOnLeave:   {models.StatusEnrolled, models.StatusExpelled},
+	models.StatusGraduated: {},
+	models.StatusExpelled:  {},
+}
+
+// initial статусы, с которыми студента можно создать
+var initial = []string{models.StatusApplicant, models.StatusEnrolled}
+
+// Next возвращает статусы, в которые можно перейти из from
+func Next(from string) []string {
+	return slices.Clone(transitions[from])
+}
+
+// Check проверяет, что переход из from в to допустим
+func Check(from, to string) error {
+	if _, ok := transitions[to]; !ok {
+		return fmt.Errorf("%w %q", ErrUnknownStatus, to)
+	}
+
+	next, ok := transitions[from]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownStatus, from)
+	}
+
+	if !slices.Contains(next, to) {
+		return fmt.Errorf("%w from %s to %s", ErrIllegalTransition, from, to)
+	}
+
+	return nil
+}
+
+// CheckInitial проверяет, что со статусом status можно создать студента
+func CheckInitial(status string) error {
+	if !slices.Contains(initial, status) {
+		return fmt.Errorf("%w: student cannot be created as %s", ErrIllegalTransition, status)
+	}
+
+	return nil
+}
+
+// Hook побочное действие после смены статуса, например уведомление или отписка от курсов.
+// Вызывается после фиксации перехода, поэтому ошибка не отменяет переход.
+type Hook func(ctx context.Context, transition models.StatusTransition) error
+
+// Hooks обработчики смены статуса
+type Hooks struct {
+	mu    sync.RWMutex
+	names []string
+	hooks []Hook
+}
+
+// NewHooks создает пустой набор обработчиков
+func NewHooks() *Hooks {
+	return &Hooks{}
+}
+
+// Register добавляет обработчик; обработчики вызываются в порядке добавления
+func (h *Hooks) Register(name string, hook Hook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.names = append(h.names, name)
+	h.hooks = append(h.hooks, hook)
+}
+
+// Run вызывает все обработчики, даже если некоторые из них завершились ошибкой,
+// и возвращает объединенные ошибки
+func (h *Hooks) Run(ctx context.Context, transition models.StatusTransition) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var errs []error
+	for i, hook := range h.hooks {
+		err := hook(ctx, transition)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("hook %s: %w", h.names[i], err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
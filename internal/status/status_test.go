@@ -0,0 +1,65 @@
+package status_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"students-crud/internal/models"
+	"students-crud/internal/status"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestCheck(t *testing.T) {
+	testCases := []struct {
+		name        string
+		from        string
+		to          string
+		expectedErr error
+	}{
+		{name: "Enroll Applicant", from: models.StatusApplicant, to: models.StatusEnrolled},
+		{name: "Take Leave", from: models.StatusEnrolled, to: models.StatusOnLeave},
+		{name: "Return From Leave", from: models.StatusOnLeave, to: models.StatusEnrolled},
+		{name: "Graduate", from: models.StatusEnrolled, to: models.StatusGraduated},
+		{name: "Expel On Leave", from: models.StatusOnLeave, to: models.StatusExpelled},
+		{name: "Graduate Applicant", from: models.StatusApplicant, to: models.StatusGraduated, expectedErr: status.ErrIllegalTransition},
+		{name: "Graduate On Leave", from: models.StatusOnLeave, to: models.StatusGraduated, expectedErr: status.ErrIllegalTransition},
+		{name: "Leave Graduated", from: models.StatusGraduated, to: models.StatusEnrolled, expectedErr: status.ErrIllegalTransition},
+		{name: "Same Status", from: models.StatusEnrolled, to: models.StatusEnrolled, expectedErr: status.ErrIllegalTransition},
+		{name: "Unknown Status", from: models.StatusEnrolled, to: "active", expectedErr: status.ErrUnknownStatus},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := status.Check(testCase.from, testCase.to)
+
+			assert.Equal(t, true, errors.Is(err, testCase.expectedErr))
+		})
+	}
+}
+
+func TestCheckInitial(t *testing.T) {
+	assert.Equal(t, nil, status.CheckInitial(models.StatusApplicant))
+	assert.Equal(t, nil, status.CheckInitial(models.StatusEnrolled))
+	assert.Equal(t, true, errors.Is(status.CheckInitial(models.StatusGraduated), status.ErrIllegalTransition))
+}
+
+func TestHooks_Run(t *testing.T) {
+	hooks := status.NewHooks()
+
+	var calls []string
+	hooks.Register("first", func(ctx context.Context, transition models.StatusTransition) error {
+		calls = append(calls, "first")
+		return errors.New("notify failed")
+	})
+	hooks.Register("second", func(ctx context.Context, transition models.StatusTransition) error {
+		calls = append(calls, "second:"+transition.To)
+		return nil
+	})
+
+	err := hooks.Run(context.Background(), models.StatusTransition{From: models.StatusEnrolled, To: models.StatusOnLeave})
+
+	assert.Equal(t, []string{"first", "second:on_leave"}, calls)
+	assert.Equal(t, "hook first: notify failed", err.Error())
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"students-crud/internal/audit"
+	"students-crud/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// writeAudit записывает событие журнала в транзакции изменения.
+// Автор и идентификатор запроса берутся из контекста.
+func writeAudit(ctx context.Context, tx pgx.Tx, studentID int, action string, before, after *models.Student) error {
+	_, err := tx.Exec(ctx, "INSERT INTO audit_events (student_id, action, actor, request_id, changes) VALUES ($1, $2, $3, $4, $5)",
+		studentID, action, audit.Actor(ctx), audit.RequestID(ctx), audit.Diff(before, after),
+	)
+
+	return err
+}
+
+// History возвращает журнал изменений студента от новых событий к старым
+func (s *Storage) History(ctx context.Context, studentID int, limit, offset int) (*models.AuditLog, error) {
+	const op = "storage.postgres.History"
+	ctx = withOperation(ctx, op)
+
+	log := &models.AuditLog{Events: []models.AuditEvent{}}
+
+	err := s.db.QueryRow(ctx, "SELECT COUNT(*) FROM audit_events WHERE student_id=$1", studentID).Scan(&log.Total)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := s.db.Query(ctx, `SELECT id, student_id, action, actor, request_id, changes, created_at
+FROM audit_events WHERE student_id=$1 ORDER BY id DESC LIMIT $2 OFFSET $3`, studentID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event models.AuditEvent
+		err = rows.Scan(&event.ID, &event.StudentID, &event.Action, &event.Actor, &event.RequestID, &event.Changes, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		log.Events = append(log.Events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return log, nil
+}
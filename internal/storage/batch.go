@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"students-crud/internal/audit"
+	"students-crud/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// auditSnapshot строит снимок отслеживаемых полей строки students, совпадающий со снимком
+// audit.Diff: незаполненные поля профиля в него не попадают
+func auditSnapshot(table string) string {
+	return fmt.Sprintf(`jsonb_strip_nulls(jsonb_build_object(
+		'name', %[1]s.name, 'email', %[1]s.email,
+		'first_name', NULLIF(%[1]s.first_name, ''), 'last_name', NULLIF(%[1]s.last_name, ''),
+		'middle_name', NULLIF(%[1]s.middle_name, ''), 'birth_date', to_char(%[1]s.birth_date, 'YYYY-MM-DD'),
+		'phone', NULLIF(%[1]s.phone, ''), 'enrollment_year', %[1]s.enrollment_year,
+		'status', NULLIF(%[1]s.status, ''), 'student_number', NULLIF(%[1]s.student_number, ''),
+		'group_id', %[1]s.group_id))`, table)
+}
+
+// upsertQuery создает или обновляет студента и пишет событие журнала одним запросом,
+// чтобы элементы пакета можно было отправлять без промежуточных ответов.
+// Как и при полном обновлении, незаполненные поля профиля сохраняют прежние значения,
+// а статус задается только при создании по умолчанию и дальше меняется переходами.
+var upsertQuery = `WITH prev AS (
+	SELECT ` + auditSnapshot("students") + ` AS snapshot FROM students WHERE email=$2 AND deleted_at IS NULL
+), up AS (
+	INSERT INTO students (name, email, first_name, last_name, middle_name, birth_date, phone, enrollment_year, student_number)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE(NULLIF($9, ''), next_student_number()))
+	ON CONFLICT (email) WHERE deleted_at IS NULL DO UPDATE SET name=EXCLUDED.name,
+		first_name=COALESCE(NULLIF(EXCLUDED.first_name, ''), students.first_name),
+		last_name=COALESCE(NULLIF(EXCLUDED.last_name, ''), students.last_name),
+		middle_name=COALESCE(NULLIF(EXCLUDED.middle_name, ''), students.middle_name),
+		birth_date=COALESCE(EXCLUDED.birth_date, students.birth_date),
+		phone=COALESCE(NULLIF(EXCLUDED.phone, ''), students.phone),
+		enrollment_year=COALESCE(EXCLUDED.enrollment_year, students.enrollment_year),
+		student_number=COALESCE(NULLIF($9, ''), students.student_number),
+		version=students.version+1
+	RETURNING id, ` + auditSnapshot("students") + ` AS snapshot, (xmax = 0) AS inserted
+), diff AS (
+	SELECT up.id, up.inserted, (
+		SELECT jsonb_object_agg(key, jsonb_build_object('before', b.value, 'after', a.value))
+		FROM jsonb_each(up.snapshot) a FULL JOIN jsonb_each(COALESCE(prev.snapshot, '{}')) b USING (key)
+		WHERE a.value IS DISTINCT FROM b.value
+	) AS changes
+	FROM up LEFT JOIN prev ON true
+), event AS (
+	INSERT INTO audit_events (student_id, action, actor, request_id, changes)
+	SELECT id, CASE WHEN inserted THEN $12 ELSE $13 END, $10, $11, changes
+	FROM diff WHERE changes IS NOT NULL
+)
+SELECT id, inserted FROM up`
+
+// Upsert создает студентов или обновляет существующих с тем же email в одной транзакции.
+// При atomic=true ошибка любого элемента откатывает весь пакет, иначе ошибочные
+// элементы пропускаются с помощью точек сохранения. Ошибка возвращается только
+// при сбое самой транзакции, результаты по элементам - в том же порядке, что и students.
+func (s *Storage) Upsert(ctx context.Context, students []models.Student, atomic bool) ([]models.BatchItemResult, error) {
+	const op = "storage.postgres.Upsert"
+	ctx = withOperation(ctx, op)
+
+	results := make([]models.BatchItemResult, len(students))
+	for i := range results {
+		results[i] = models.BatchItemResult{Index: i, Status: models.BatchSkipped}
+	}
+
+	if len(students) == 0 {
+		return results, nil
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	for start := 0; start < len(students); {
+		failed, err := upsertChunk(ctx, tx, students, start, results, !atomic)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if failed < 0 {
+			break
+		}
+
+		if atomic {
+			for i := range results {
+				if results[i].Status != models.BatchFailed {
+					results[i] = models.BatchItemResult{Index: i, Status: models.BatchRolledBack}
+				}
+			}
+			return results, nil
+		}
+
+		_, err = tx.Exec(ctx, "ROLLBACK TO SAVEPOINT batch_item")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		start = failed + 1
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return results, nil
+}
+
+// upsertChunk отправляет элементы, начиная со start, одним пакетом запросов.
+// Возвращает индекс первого упавшего элемента или -1, если ошибок не было.
+// После ошибки транзакция остается в прерванном состоянии до отката к точке сохранения.
+func upsertChunk(ctx context.Context, tx pgx.Tx, students []models.Student, start int, results []models.BatchItemResult, savepoints bool) (int, error) {
+	batch := &pgx.Batch{}
+	for _, student := range students[start:] {
+		if savepoints {
+			batch.Queue("SAVEPOINT batch_item")
+		}
+		batch.Queue(upsertQuery, student.Name, student.Email, student.FirstName, student.LastName, student.MiddleName,
+			student.BirthDate, student.Phone, student.EnrollmentYear, student.StudentNumber,
+			audit.Actor(ctx), audit.RequestID(ctx), models.AuditCreate, models.AuditUpdate)
+		if savepoints {
+			batch.Queue("RELEASE SAVEPOINT batch_item")
+		}
+	}
+
+	br := tx.SendBatch(ctx, batch)
+	defer br.Close()
+
+	for i := start; i < len(students); i++ {
+		if savepoints {
+			_, err := br.Exec()
+			if err != nil {
+				return -1, err
+			}
+		}
+
+		var (
+			id       int
+			inserted bool
+		)
+		err := br.QueryRow().Scan(&id, &inserted)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if !errors.As(err, &pgErr) {
+				return -1, err
+			}
+
+			reason := "failed to save student"
+			if domainErr := classifyError(err); domainErr != nil {
+				reason = domainErr.Error()
+			}
+			results[i] = models.BatchItemResult{Index: i, Status: models.BatchFailed, Error: reason}
+
+			return i, nil
+		}
+
+		results[i] = models.BatchItemResult{Index: i, ID: id, Status: models.BatchUpdated}
+		if inserted {
+			results[i].Status = models.BatchCreated
+		}
+
+		if savepoints {
+			_, err = br.Exec()
+			if err != nil {
+				return -1, err
+			}
+		}
+	}
+
+	return -1, nil
+}
@@ -0,0 +1,338 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"students-crud/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// courseColumns колонки курса вместе с числом записанных действующих студентов, порядок соответствует scanCourse.
+// Мягко удаленные студенты места не занимают, как и в CourseStudents.
+const courseColumns = `c.id, c.code, c.title, c.term, c.credits, c.capacity,
+	(SELECT COUNT(*) FROM enrollments ce JOIN students cs ON cs.id = ce.student_id
+		WHERE ce.course_id = c.id AND cs.deleted_at IS NULL)`
+
+// rowQuerier общая часть пула и транзакции для запросов одной строки
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func scanCourse(row pgx.Row, course *models.Course) error {
+	return row.Scan(&course.ID, &course.Code, &course.Title, &course.Term, &course.Credits, &course.Capacity, &course.Enrolled)
+}
+
+// CreateCourse создает курс и возвращает его ID
+func (s *Storage) CreateCourse(ctx context.Context, course *models.Course) (int, error) {
+	const op = "storage.postgres.CreateCourse"
+	ctx = withOperation(ctx, op)
+
+	err := s.db.QueryRow(ctx,
+		"INSERT INTO courses (code, title, term, credits, capacity) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+		course.Code, course.Title, course.Term, course.Credits, course.Capacity,
+	).Scan(&course.ID)
+	if err != nil {
+		return 0, wrapError(op, err)
+	}
+
+	return course.ID, nil
+}
+
+// ReadCourse читает курс по ID
+func (s *Storage) ReadCourse(ctx context.Context, id int) (*models.Course, error) {
+	const op = "storage.postgres.ReadCourse"
+	ctx = withOperation(ctx, op)
+
+	course := &models.Course{}
+	err := scanCourse(s.db.QueryRow(ctx, "SELECT "+courseColumns+" FROM courses c WHERE c.id=$1", id), course)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrCourseNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return course, nil
+}
+
+// ListCourses возвращает страницу курсов, упорядоченных по семестру и коду
+func (s *Storage) ListCourses(ctx context.Context, limit, offset int) (*models.CourseList, error) {
+	const op = "storage.postgres.ListCourses"
+	ctx = withOperation(ctx, op)
+
+	list := &models.CourseList{Courses: []models.Course{}}
+	err := s.db.QueryRow(ctx, "SELECT COUNT(*) FROM courses").Scan(&list.Total)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := s.db.Query(ctx, "SELECT "+courseColumns+" FROM courses c ORDER BY c.term, c.code, c.id LIMIT $1 OFFSET $2", limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var course models.Course
+		err = scanCourse(rows, &course)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		list.Courses = append(list.Courses, course)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return list, nil
+}
+
+// UpdateCourse обновляет курс. Уменьшение вместимости не отчисляет уже записанных
+// студентов, но новые записи не принимаются, пока мест не станет меньше лимита.
+func (s *Storage) UpdateCourse(ctx context.Context, course *models.Course) error {
+	const op = "storage.postgres.UpdateCourse"
+	ctx = withOperation(ctx, op)
+
+	tag, err := s.db.Exec(ctx,
+		"UPDATE courses SET code=$1, title=$2, term=$3, credits=$4, capacity=$5 WHERE id=$6",
+		course.Code, course.Title, course.Term, course.Credits, course.Capacity, course.ID,
+	)
+	if err != nil {
+		return wrapError(op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrCourseNotFound)
+	}
+
+	return nil
+}
+
+// DeleteCourse удаляет курс без записанных студентов
+func (s *Storage) DeleteCourse(ctx context.Context, id int) error {
+	const op = "storage.postgres.DeleteCourse"
+	ctx = withOperation(ctx, op)
+
+	tag, err := s.db.Exec(ctx, "DELETE FROM courses WHERE id=$1", id)
+	if err != nil {
+		return wrapError(op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrCourseNotFound)
+	}
+
+	return nil
+}
+
+// Enroll записывает студента на курс. Строка курса блокируется до конца транзакции,
+// поэтому одновременные записи не превысят вместимость.
+func (s *Storage) Enroll(ctx context.Context, studentID, courseID int) (*models.Enrollment, error) {
+	const op = "storage.postgres.Enroll"
+	ctx = withOperation(ctx, op)
+
+	enrollment := &models.Enrollment{StudentID: studentID, CourseID: courseID}
+
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		err := checkStudent(ctx, tx, studentID, "FOR SHARE")
+		if err != nil {
+			return err
+		}
+
+		var capacity int
+		err = tx.QueryRow(ctx, "SELECT capacity FROM courses WHERE id=$1 FOR UPDATE", courseID).Scan(&capacity)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrCourseNotFound
+			}
+			return err
+		}
+
+		var (
+			enrolled int
+			exists   bool
+		)
+		// Места считаются только за действующими студентами
+		err = tx.QueryRow(ctx, `
+			SELECT COUNT(*) FILTER (WHERE s.deleted_at IS NULL), COALESCE(bool_or(e.student_id = $2), false)
+			FROM enrollments e JOIN students s ON s.id = e.student_id
+			WHERE e.course_id=$1`,
+			courseID, studentID,
+		).Scan(&enrolled, &exists)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrAlreadyEnrolled
+		}
+
+		if capacity > 0 && enrolled >= capacity {
+			return ErrCourseFull
+		}
+
+		return tx.QueryRow(ctx,
+			"INSERT INTO enrollments (student_id, course_id) VALUES ($1, $2) RETURNING id, enrolled_at",
+			studentID, courseID,
+		).Scan(&enrollment.ID, &enrollment.EnrolledAt)
+	})
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	return enrollment, nil
+}
+
+// Unenroll отписывает студента от курса
+func (s *Storage) Unenroll(ctx context.Context, studentID, courseID int) error {
+	const op = "storage.postgres.Unenroll"
+	ctx = withOperation(ctx, op)
+
+	tag, err := s.db.Exec(ctx, "DELETE FROM enrollments WHERE student_id=$1 AND course_id=$2", studentID, courseID)
+	if err != nil {
+		return wrapError(op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrEnrollmentNotFound)
+	}
+
+	return nil
+}
+
+// StudentCourses возвращает курсы студента, упорядоченные по семестру и коду
+func (s *Storage) StudentCourses(ctx context.Context, studentID int) ([]models.Enrollment, error) {
+	const op = "storage.postgres.StudentCourses"
+	ctx = withOperation(ctx, op)
+
+	err := checkStudent(ctx, s.db, studentID, "")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT e.id, e.enrolled_at, `+courseColumns+`
+		FROM enrollments e
+		JOIN courses c ON c.id = e.course_id
+		WHERE e.student_id=$1
+		ORDER BY c.term, c.code, c.id`, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	enrollments := []models.Enrollment{}
+	for rows.Next() {
+		enrollment := models.Enrollment{StudentID: studentID, Course: &models.Course{}}
+		course := enrollment.Course
+		err = rows.Scan(&enrollment.ID, &enrollment.EnrolledAt,
+			&course.ID, &course.Code, &course.Title, &course.Term, &course.Credits, &course.Capacity, &course.Enrolled)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		enrollment.CourseID = course.ID
+		enrollments = append(enrollments, enrollment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return enrollments, nil
+}
+
+// CourseStudents возвращает страницу действующих студентов курса в порядке записи
+func (s *Storage) CourseStudents(ctx context.Context, courseID, limit, offset int) (*models.EnrollmentList, error) {
+	const op = "storage.postgres.CourseStudents"
+	ctx = withOperation(ctx, op)
+
+	list := &models.EnrollmentList{Enrollments: []models.Enrollment{}}
+
+	var exists bool
+	err := s.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM courses WHERE id=$1),
+			(SELECT COUNT(*) FROM enrollments e JOIN students s ON s.id = e.student_id WHERE e.course_id=$1 AND s.deleted_at IS NULL)`,
+		courseID).Scan(&exists, &list.Total)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s: %w", op, ErrCourseNotFound)
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT e.id, e.enrolled_at, s.id, s.name, s.email, s.version
+		FROM enrollments e
+		JOIN students s ON s.id = e.student_id
+		WHERE e.course_id=$1 AND s.deleted_at IS NULL
+		ORDER BY e.enrolled_at, e.id
+		LIMIT $2 OFFSET $3`, courseID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		enrollment := models.Enrollment{CourseID: courseID, Student: &models.Student{}}
+		student := enrollment.Student
+		err = rows.Scan(&enrollment.ID, &enrollment.EnrolledAt, &student.ID, &student.Name, &student.Email, &student.Version)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		enrollment.StudentID = student.ID
+		list.Enrollments = append(list.Enrollments, enrollment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return list, nil
+}
+
+// checkSeats проверяет, что для мягко удаленного студента есть места на всех его курсах.
+// Строки курсов блокируются до конца транзакции, как при записи в Enroll,
+// поэтому одновременная запись не займет освобожденное место.
+func checkSeats(ctx context.Context, tx pgx.Tx, studentID int) error {
+	_, err := tx.Exec(ctx, `
+		SELECT 1 FROM courses c JOIN enrollments e ON e.course_id = c.id
+		WHERE e.student_id=$1 ORDER BY c.id FOR UPDATE OF c`, studentID)
+	if err != nil {
+		return err
+	}
+
+	// Сам студент еще удален и в числе занятых мест не учитывается
+	var full bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM courses c JOIN enrollments e ON e.course_id = c.id
+			WHERE e.student_id=$1 AND c.capacity > 0 AND c.capacity <= (
+				SELECT COUNT(*) FROM enrollments ce JOIN students cs ON cs.id = ce.student_id
+				WHERE ce.course_id = c.id AND cs.deleted_at IS NULL))`, studentID).Scan(&full)
+	if err != nil {
+		return err
+	}
+	if full {
+		return ErrCourseFull
+	}
+
+	return nil
+}
+
+// checkStudent проверяет, что действующий студент существует; lock добавляется к запросу
+// как есть, например FOR SHARE, чтобы студента не удалили до конца транзакции
+func checkStudent(ctx context.Context, q rowQuerier, id int, lock string) error {
+	var found int
+	err := q.QueryRow(ctx, "SELECT 1 FROM students WHERE id=$1 AND deleted_at IS NULL "+lock, id).Scan(&found)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// seatsTx отвечает на запросы checkSeats и записывает их; остальные методы pgx.Tx не вызываются
+type seatsTx struct {
+	pgx.Tx
+	full    bool
+	queries []string
+}
+
+func (tx *seatsTx) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	tx.queries = append(tx.queries, sql)
+	return pgconn.NewCommandTag("SELECT 1"), nil
+}
+
+func (tx *seatsTx) QueryRow(_ context.Context, sql string, _ ...any) pgx.Row {
+	tx.queries = append(tx.queries, sql)
+	return seatsRow{full: tx.full}
+}
+
+type seatsRow struct {
+	full bool
+}
+
+func (r seatsRow) Scan(dest ...any) error {
+	*dest[0].(*bool) = r.full
+	return nil
+}
+
+func TestCheckSeats(t *testing.T) {
+	testCases := []struct {
+		name     string
+		full     bool
+		expected error
+	}{
+		{name: "Seats Left", full: false, expected: nil},
+		{name: "Course Full", full: true, expected: ErrCourseFull},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			tx := &seatsTx{full: testCase.full}
+
+			err := checkSeats(context.Background(), tx, 1)
+
+			assert.Equal(t, testCase.expected, err)
+			assert.Equal(t, 2, len(tx.queries))
+			// Курсы блокируются до подсчета мест
+			assert.Equal(t, true, strings.Contains(tx.queries[0], "FOR UPDATE OF c"))
+			assert.Equal(t, true, strings.Contains(tx.queries[1], "cs.deleted_at IS NULL"))
+		})
+	}
+}
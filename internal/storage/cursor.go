@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// cursor позиция последней записи страницы при keyset-пагинации
+type cursor struct {
+	SortBy string `json:"s"`
+	Desc   bool   `json:"d,omitempty"`
+	Value  string `json:"v,omitempty"`
+	ID     int    `json:"id"`
+}
+
+// encodeCursor упаковывает курсор в непрозрачный токен
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// matches сообщает, что курсор выдан для той же сортировки и того же направления
+func (c cursor) matches(sortBy string, desc bool) bool {
+	return c.SortBy == sortBy && c.Desc == desc
+}
+
+// decodeCursor распаковывает токен, полученный от encodeCursor
+func decodeCursor(token string) (cursor, error) {
+	var c cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+
+	err = json.Unmarshal(data, &c)
+	if err != nil || c.ID <= 0 {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
+}
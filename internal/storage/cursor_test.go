@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestCursor(t *testing.T) {
+	token := encodeCursor(cursor{SortBy: "name", Desc: true, Value: "Ivan", ID: 7})
+
+	c, err := decodeCursor(token)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, cursor{SortBy: "name", Desc: true, Value: "Ivan", ID: 7}, c)
+
+	testCases := []struct {
+		name     string
+		sortBy   string
+		desc     bool
+		expected bool
+	}{
+		{name: "Same Sort", sortBy: "name", desc: true, expected: true},
+		{name: "Other Direction", sortBy: "name", desc: false, expected: false},
+		{name: "Other Field", sortBy: "email", desc: true, expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, c.matches(testCase.sortBy, testCase.desc))
+		})
+	}
+
+	_, err = decodeCursor("not-a-cursor")
+	assert.Equal(t, ErrInvalidCursor, err)
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"students-crud/internal/config"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// connString собирает строку подключения из cfg.DSN или отдельных полей
+// и дополняет ее параметрами TLS из конфигурации. Как и в configurePool, параметры,
+// указанные в строке подключения, важнее значений конфигурации.
+func connString(cfg *config.Storage) (string, error) {
+	var params [][2]string
+	if cfg.DSN == "" {
+		params = append(params,
+			[2]string{"host", cfg.Host},
+			[2]string{"port", cfg.Port},
+			[2]string{"user", cfg.User},
+			[2]string{"password", cfg.Password},
+			[2]string{"dbname", cfg.DB},
+		)
+	}
+
+	for _, p := range [][2]string{
+		{"sslmode", cfg.SSLMode},
+		{"sslrootcert", cfg.SSLRootCert},
+		{"sslcert", cfg.SSLCert},
+		{"sslkey", cfg.SSLKey},
+	} {
+		if p[1] != "" {
+			params = append(params, p)
+		}
+	}
+
+	if strings.HasPrefix(cfg.DSN, "postgres://") || strings.HasPrefix(cfg.DSN, "postgresql://") {
+		u, err := url.Parse(cfg.DSN)
+		if err != nil {
+			return "", err
+		}
+
+		query := u.Query()
+		for _, p := range params {
+			if !query.Has(p[0]) {
+				query.Set(p[0], p[1])
+			}
+		}
+		u.RawQuery = query.Encode()
+
+		return u.String(), nil
+	}
+
+	inDSN := keywordParams(cfg.DSN)
+
+	parts := make([]string, 0, len(params)+1)
+	if cfg.DSN != "" {
+		parts = append(parts, cfg.DSN)
+	}
+	for _, p := range params {
+		if p[1] != "" && !inDSN[p[0]] {
+			parts = append(parts, p[0]+"="+quoteValue(p[1]))
+		}
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// keywordParams возвращает имена параметров строки подключения вида key=value.
+// Значения в кавычках пропускаются по правилам libpq, чтобы "sslmode=" внутри
+// пароля не принималось за параметр.
+func keywordParams(dsn string) map[string]bool {
+	params := make(map[string]bool)
+
+	for dsn = strings.TrimSpace(dsn); dsn != ""; dsn = strings.TrimSpace(dsn) {
+		key, rest, ok := strings.Cut(dsn, "=")
+		if !ok {
+			break
+		}
+		params[strings.TrimSpace(key)] = true
+
+		rest = strings.TrimLeft(rest, " \t\n")
+		if !strings.HasPrefix(rest, "'") {
+			end := strings.IndexAny(rest, " \t\n")
+			if end < 0 {
+				break
+			}
+			dsn = rest[end:]
+			continue
+		}
+
+		// Значение в кавычках заканчивается первой неэкранированной кавычкой
+		i := 1
+		for i < len(rest) && rest[i] != '\'' {
+			if rest[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		dsn = rest[min(i+1, len(rest)):]
+	}
+
+	return params
+}
+
+// quoteValue заключает значение в кавычки по правилам libpq, чтобы пробелы,
+// кавычки и обратные слэши в пароле не ломали строку подключения
+func quoteValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// configurePool применяет к пулу заданные в конфигурации размеры, сроки жизни
+// соединений и параметры сеанса. Как и в connString, параметры, указанные в строке
+// подключения, важнее значений конфигурации, в том числе значений по умолчанию.
+func configurePool(pc *pgxpool.Config, cfg *config.Storage) {
+	inDSN := dsnParams(pc)
+
+	if cfg.MaxConns > 0 && !inDSN["pool_max_conns"] {
+		pc.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 && !inDSN["pool_min_conns"] {
+		pc.MinConns = cfg.MinConns
+	}
+	if cfg.MaxConnLifetime > 0 && !inDSN["pool_max_conn_lifetime"] {
+		pc.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 && !inDSN["pool_max_conn_idle_time"] {
+		pc.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	if cfg.HealthCheckPeriod > 0 && !inDSN["pool_health_check_period"] {
+		pc.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+	if cfg.ConnectTimeout > 0 && !inDSN["connect_timeout"] {
+		pc.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+	}
+
+	if cfg.ApplicationName != "" && !inDSN["application_name"] {
+		pc.ConnConfig.RuntimeParams["application_name"] = cfg.ApplicationName
+	}
+	if cfg.StatementTimeout > 0 && !inDSN["statement_timeout"] {
+		pc.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+}
+
+// dsnParams возвращает параметры пула и сеанса, заданные строкой подключения
+// или переменными окружения libpq. pgxpool.ParseConfig убирает параметры пула
+// из RuntimeParams, поэтому строка разбирается повторно без них.
+func dsnParams(pc *pgxpool.Config) map[string]bool {
+	params := map[string]bool{
+		// connect_timeout=0 означает ожидание без ограничения и не отличается от отсутствия параметра
+		"connect_timeout": pc.ConnConfig.ConnectTimeout > 0,
+	}
+
+	for name := range pc.ConnConfig.RuntimeParams {
+		params[name] = true
+	}
+
+	connConfig, err := pgconn.ParseConfig(pc.ConnString())
+	if err != nil {
+		return params
+	}
+	for name := range connConfig.RuntimeParams {
+		params[name] = true
+	}
+
+	return params
+}
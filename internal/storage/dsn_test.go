@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"students-crud/internal/config"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestConnString(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cfg      config.Storage
+		expected string
+	}{
+		{
+			name:     "Fields",
+			cfg:      config.Storage{Host: "db", Port: "5432", User: "app", Password: `p@ss' w\rd`, DB: "students"},
+			expected: `host='db' port='5432' user='app' password='p@ss\' w\\rd' dbname='students'`,
+		},
+		{
+			name:     "Fields With TLS",
+			cfg:      config.Storage{Host: "db", Port: "5432", User: "app", DB: "students", SSLMode: "verify-full", SSLRootCert: "/etc/ca.pem"},
+			expected: `host='db' port='5432' user='app' dbname='students' sslmode='verify-full' sslrootcert='/etc/ca.pem'`,
+		},
+		{
+			name:     "URL With TLS",
+			cfg:      config.Storage{DSN: "postgres://app:secret@db:5432/students?connect_timeout=3", SSLMode: "require"},
+			expected: "postgres://app:secret@db:5432/students?connect_timeout=3&sslmode=require",
+		},
+		{
+			name:     "URL TLS Wins",
+			cfg:      config.Storage{DSN: "postgres://app@db/students?sslmode=verify-full", SSLMode: "require", SSLRootCert: "/etc/ca.pem"},
+			expected: "postgres://app@db/students?sslmode=verify-full&sslrootcert=%2Fetc%2Fca.pem",
+		},
+		{
+			name:     "Keyword DSN",
+			cfg:      config.Storage{DSN: "host=db dbname=students", SSLMode: "disable"},
+			expected: "host=db dbname=students sslmode='disable'",
+		},
+		{
+			name:     "Keyword DSN Quoted Value",
+			cfg:      config.Storage{DSN: `host=db password='it\'s sslmode=off'`, SSLMode: "require"},
+			expected: `host=db password='it\'s sslmode=off' sslmode='require'`,
+		},
+		{
+			name:     "Keyword DSN TLS Wins",
+			cfg:      config.Storage{DSN: "host=db password='x sslmode=off' sslmode = verify-full", SSLMode: "require", SSLCert: "/etc/client.pem"},
+			expected: "host=db password='x sslmode=off' sslmode = verify-full sslcert='/etc/client.pem'",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dsn, err := connString(&testCase.cfg)
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expected, dsn)
+		})
+	}
+}
+
+func TestConnString_PasswordRoundTrip(t *testing.T) {
+	password := `p@ss' w\rd =?&`
+
+	dsn, err := connString(&config.Storage{Host: "db", Port: "5432", User: "app", Password: password, DB: "students"})
+	assert.Equal(t, nil, err)
+
+	pc, err := pgxpool.ParseConfig(dsn)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, password, pc.ConnConfig.Password)
+	assert.Equal(t, "students", pc.ConnConfig.Database)
+}
+
+func TestConfigurePool(t *testing.T) {
+	pc, err := pgxpool.ParseConfig("host=db dbname=students")
+	assert.Equal(t, nil, err)
+
+	configurePool(pc, &config.Storage{
+		MaxConns:          20,
+		MinConns:          2,
+		MaxConnLifetime:   time.Hour,
+		HealthCheckPeriod: 30 * time.Second,
+		ConnectTimeout:    5 * time.Second,
+		ApplicationName:   "students-crud",
+		StatementTimeout:  1500 * time.Millisecond,
+	})
+
+	assert.Equal(t, int32(20), pc.MaxConns)
+	assert.Equal(t, int32(2), pc.MinConns)
+	assert.Equal(t, time.Hour, pc.MaxConnLifetime)
+	assert.Equal(t, 30*time.Second, pc.HealthCheckPeriod)
+	assert.Equal(t, 5*time.Second, pc.ConnConfig.ConnectTimeout)
+	assert.Equal(t, "students-crud", pc.ConnConfig.RuntimeParams["application_name"])
+	assert.Equal(t, "1500", pc.ConnConfig.RuntimeParams["statement_timeout"])
+}
+
+func TestConfigurePool_DSNParams(t *testing.T) {
+	cfg := &config.Storage{
+		MaxConns:          10,
+		MaxConnLifetime:   time.Hour,
+		MaxConnIdleTime:   30 * time.Minute,
+		HealthCheckPeriod: time.Minute,
+		ConnectTimeout:    5 * time.Second,
+		ApplicationName:   "students-crud",
+	}
+
+	testCases := []struct {
+		name string
+		dsn  string
+	}{
+		{
+			name: "Keyword DSN",
+			dsn:  "host=db dbname=students pool_max_conns=3 pool_max_conn_lifetime=10m connect_timeout=2 application_name=reports",
+		},
+		{
+			name: "URL",
+			dsn:  "postgres://app@db/students?pool_max_conns=3&pool_max_conn_lifetime=10m&connect_timeout=2&application_name=reports",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			pc, err := pgxpool.ParseConfig(testCase.dsn)
+			assert.Equal(t, nil, err)
+
+			configurePool(pc, cfg)
+
+			// Из строки подключения
+			assert.Equal(t, int32(3), pc.MaxConns)
+			assert.Equal(t, 10*time.Minute, pc.MaxConnLifetime)
+			assert.Equal(t, 2*time.Second, pc.ConnConfig.ConnectTimeout)
+			assert.Equal(t, "reports", pc.ConnConfig.RuntimeParams["application_name"])
+
+			// Из конфигурации
+			assert.Equal(t, 30*time.Minute, pc.MaxConnIdleTime)
+			assert.Equal(t, time.Minute, pc.HealthCheckPeriod)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrNotFound        = errors.New("student not found")
+	ErrVersionConflict = errors.New("version conflict")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrEmailTaken      = errors.New("email already taken")
+	ErrAlreadyExists   = errors.New("record already exists")
+	ErrMissingField    = errors.New("required field is missing")
+	ErrValueTooLong    = errors.New("value too long")
+	ErrInvalidValue    = errors.New("invalid value")
+	ErrNumberTaken     = errors.New("student number already taken")
+
+	ErrCourseNotFound     = errors.New("course not found")
+	ErrCourseExists       = errors.New("course with this code already exists in the term")
+	ErrCourseInUse        = errors.New("course has enrolled students")
+	ErrCourseFull         = errors.New("course is full")
+	ErrEnrollmentNotFound = errors.New("enrollment not found")
+	ErrAlreadyEnrolled    = errors.New("student already enrolled in the course")
+
+	ErrGroupNotFound = errors.New("group not found")
+	ErrGroupExists   = errors.New("group with this name already exists")
+	ErrGroupNotEmpty = errors.New("group has students")
+)
+
+// Коды ошибок Postgres, см. https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation        = "23505"
+	pgNotNullViolation       = "23502"
+	pgForeignKeyViolation    = "23503"
+	pgCheckViolation         = "23514"
+	pgStringDataRightTrunc   = "22001"
+	pgInvalidTextRepresent   = "22P02"
+	pgUndefinedTable         = "42P01"
+	pgSerializationFailure   = "40001"
+	pgDeadlockDetected       = "40P01"
+	studentsEmailConstraint  = "students_email_active_key"
+	studentsNumberConstraint = "students_student_number_key"
+
+	coursesCodeConstraint       = "courses_code_term_key"
+	enrollmentsUniqueConstraint = "enrollments_student_course_key"
+	enrollmentsCourseConstraint = "enrollments_course_id_fkey"
+	groupsNameConstraint        = "groups_name_key"
+	studentsGroupConstraint     = "students_group_id_fkey"
+)
+
+// classifyError переводит ошибку Postgres в доменную ошибку хранилища.
+// Возвращает nil, если ошибка не относится к известным классам.
+func classifyError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		switch pgErr.ConstraintName {
+		case studentsEmailConstraint:
+			return ErrEmailTaken
+		case studentsNumberConstraint:
+			return ErrNumberTaken
+		case coursesCodeConstraint:
+			return ErrCourseExists
+		case enrollmentsUniqueConstraint:
+			return ErrAlreadyEnrolled
+		case groupsNameConstraint:
+			return ErrGroupExists
+		}
+		return ErrAlreadyExists
+	case pgForeignKeyViolation:
+		switch pgErr.ConstraintName {
+		case enrollmentsCourseConstraint:
+			return ErrCourseInUse
+		case studentsGroupConstraint:
+			return ErrGroupNotEmpty
+		}
+	case pgNotNullViolation:
+		return ErrMissingField
+	case pgStringDataRightTrunc:
+		return ErrValueTooLong
+	case pgCheckViolation, pgInvalidTextRepresent:
+		return ErrInvalidValue
+	}
+
+	return nil
+}
+
+// wrapError оборачивает ошибку именем операции, сохраняя доменную ошибку в цепочке
+func wrapError(op string, err error) error {
+	if domainErr := classifyError(err); domainErr != nil {
+		return fmt.Errorf("%s: %w: %w", op, domainErr, err)
+	}
+
+	return fmt.Errorf("%s: %w", op, err)
+}
@@ -0,0 +1,279 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"students-crud/internal/audit"
+	"students-crud/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// groupColumns колонки группы вместе с числом действующих студентов, порядок соответствует scanGroup
+const groupColumns = `g.id, g.name, g.description,
+	(SELECT COUNT(*) FROM students s WHERE s.group_id = g.id AND s.deleted_at IS NULL)`
+
+func scanGroup(row pgx.Row, group *models.Group) error {
+	return row.Scan(&group.ID, &group.Name, &group.Description, &group.Size)
+}
+
+// CreateGroup создает группу и возвращает ее ID
+func (s *Storage) CreateGroup(ctx context.Context, group *models.Group) (int, error) {
+	const op = "storage.postgres.CreateGroup"
+	ctx = withOperation(ctx, op)
+
+	err := s.db.QueryRow(ctx, "INSERT INTO groups (name, description) VALUES ($1, $2) RETURNING id",
+		group.Name, group.Description,
+	).Scan(&group.ID)
+	if err != nil {
+		return 0, wrapError(op, err)
+	}
+
+	return group.ID, nil
+}
+
+// ReadGroup читает группу по ID
+func (s *Storage) ReadGroup(ctx context.Context, id int) (*models.Group, error) {
+	const op = "storage.postgres.ReadGroup"
+	ctx = withOperation(ctx, op)
+
+	group := &models.Group{}
+	err := scanGroup(s.db.QueryRow(ctx, "SELECT "+groupColumns+" FROM groups g WHERE g.id=$1", id), group)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrGroupNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return group, nil
+}
+
+// ListGroups возвращает страницу групп, упорядоченных по названию
+func (s *Storage) ListGroups(ctx context.Context, limit, offset int) (*models.GroupList, error) {
+	const op = "storage.postgres.ListGroups"
+	ctx = withOperation(ctx, op)
+
+	list := &models.GroupList{Groups: []models.Group{}}
+	err := s.db.QueryRow(ctx, "SELECT COUNT(*) FROM groups").Scan(&list.Total)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := s.db.Query(ctx, "SELECT "+groupColumns+" FROM groups g ORDER BY g.name, g.id LIMIT $1 OFFSET $2", limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var group models.Group
+		err = scanGroup(rows, &group)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		list.Groups = append(list.Groups, group)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return list, nil
+}
+
+// UpdateGroup обновляет название и описание группы
+func (s *Storage) UpdateGroup(ctx context.Context, group *models.Group) error {
+	const op = "storage.postgres.UpdateGroup"
+	ctx = withOperation(ctx, op)
+
+	tag, err := s.db.Exec(ctx, "UPDATE groups SET name=$1, description=$2 WHERE id=$3", group.Name, group.Description, group.ID)
+	if err != nil {
+		return wrapError(op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrGroupNotFound)
+	}
+
+	return nil
+}
+
+// DeleteGroup удаляет группу. Группу, в которой числятся студенты, включая
+// мягко удаленных, удалить нельзя: их нужно сначала перевести.
+func (s *Storage) DeleteGroup(ctx context.Context, id int) error {
+	const op = "storage.postgres.DeleteGroup"
+	ctx = withOperation(ctx, op)
+
+	tag, err := s.db.Exec(ctx, "DELETE FROM groups WHERE id=$1", id)
+	if err != nil {
+		return wrapError(op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrGroupNotFound)
+	}
+
+	return nil
+}
+
+// GroupRoster возвращает страницу действующих студентов группы с сортировкой
+func (s *Storage) GroupRoster(ctx context.Context, groupID int, params models.RosterParams) (*models.StudentList, error) {
+	const op = "storage.postgres.GroupRoster"
+	ctx = withOperation(ctx, op)
+
+	column, ok := sortColumns[params.SortBy]
+	if !ok {
+		column = "id"
+	}
+
+	direction := "ASC"
+	if params.Desc {
+		direction = "DESC"
+	}
+
+	list := &models.StudentList{Students: []models.Student{}}
+
+	var exists bool
+	err := s.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM groups WHERE id=$1),
+			(SELECT COUNT(*) FROM students WHERE group_id=$1 AND deleted_at IS NULL)`,
+		groupID).Scan(&exists, &list.Total)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s: %w", op, ErrGroupNotFound)
+	}
+
+	query := "SELECT " + studentColumns + " FROM students WHERE group_id=$1 AND deleted_at IS NULL"
+	if column == "id" {
+		query += fmt.Sprintf(" ORDER BY id %s", direction)
+	} else {
+		query += fmt.Sprintf(" ORDER BY %s %s, id %s", column, direction, direction)
+	}
+	query += " LIMIT $2 OFFSET $3"
+
+	rows, err := s.db.Query(ctx, query, groupID, params.Limit, params.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var student models.Student
+		err = scanStudent(rows, &student)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		list.Students = append(list.Students, student)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return list, nil
+}
+
+// MoveStudent переводит студента в группу move.GroupID или исключает из группы, если он nil,
+// и записывает перевод в историю и журнал аудита. Перевод в текущую группу ничего не меняет.
+func (s *Storage) MoveStudent(ctx context.Context, studentID int, move models.GroupMove) (*models.Student, error) {
+	const op = "storage.postgres.MoveStudent"
+	ctx = withOperation(ctx, op)
+
+	var student *models.Student
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		var err error
+		student, err = lockStudent(ctx, tx, studentID, false)
+		if err != nil {
+			return err
+		}
+
+		if sameGroup(student.GroupID, move.GroupID) {
+			return nil
+		}
+
+		if move.GroupID != nil {
+			var found int
+			err = tx.QueryRow(ctx, "SELECT 1 FROM groups WHERE id=$1 FOR SHARE", *move.GroupID).Scan(&found)
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return ErrGroupNotFound
+				}
+				return err
+			}
+		}
+
+		before := *student
+		err = tx.QueryRow(ctx, "UPDATE students SET group_id=$1, version=version+1 WHERE id=$2 RETURNING version",
+			move.GroupID, studentID,
+		).Scan(&student.Version)
+		if err != nil {
+			return err
+		}
+		student.GroupID = move.GroupID
+
+		_, err = tx.Exec(ctx, `INSERT INTO group_moves (student_id, from_group_id, to_group_id, reason, actor, request_id)
+VALUES ($1, $2, $3, $4, $5, $6)`,
+			studentID, before.GroupID, move.GroupID, move.Reason, audit.Actor(ctx), audit.RequestID(ctx),
+		)
+		if err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, studentID, models.AuditGroupMove, &before, student)
+	})
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	return student, nil
+}
+
+// sameGroup сравнивает ссылки на группу с учетом отсутствия группы
+func sameGroup(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return *a == *b
+}
+
+// GroupHistory возвращает историю переводов студента от новых к старым
+func (s *Storage) GroupHistory(ctx context.Context, studentID, limit, offset int) (*models.GroupMoveLog, error) {
+	const op = "storage.postgres.GroupHistory"
+	ctx = withOperation(ctx, op)
+
+	log := &models.GroupMoveLog{Moves: []models.GroupMoveEvent{}}
+
+	err := s.db.QueryRow(ctx, "SELECT COUNT(*) FROM group_moves WHERE student_id=$1", studentID).Scan(&log.Total)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := s.db.Query(ctx, `SELECT id, student_id, from_group_id, to_group_id, reason, actor, request_id, moved_at
+FROM group_moves WHERE student_id=$1 ORDER BY id DESC LIMIT $2 OFFSET $3`, studentID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event models.GroupMoveEvent
+		err = rows.Scan(&event.ID, &event.StudentID, &event.FromGroupID, &event.ToGroupID, &event.Reason, &event.Actor, &event.RequestID, &event.MovedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		log.Moves = append(log.Moves, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return log, nil
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"students-crud/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Ping проверяет, что база данных отвечает
+func (s *Storage) Ping(ctx context.Context) error {
+	const op = "storage.postgres.Ping"
+	ctx = withOperation(ctx, op)
+
+	err := s.pool.Ping(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MigrationStatus читает текущую версию схемы из таблицы golang-migrate
+func (s *Storage) MigrationStatus(ctx context.Context) (*models.MigrationStatus, error) {
+	const op = "storage.postgres.MigrationStatus"
+	ctx = withOperation(ctx, op)
+
+	status := &models.MigrationStatus{Expected: s.schemaVersion}
+	err := s.pool.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&status.Version, &status.Dirty)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		// Таблицы нет, пока миграции ни разу не запускались
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUndefinedTable {
+			return status, nil
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return status, nil
+}
+
+// PoolStats возвращает состояние пула соединений
+func (s *Storage) PoolStats() models.PoolStats {
+	stat := s.pool.Stat()
+
+	return models.PoolStats{
+		AcquiredConns:     stat.AcquiredConns(),
+		IdleConns:         stat.IdleConns(),
+		TotalConns:        stat.TotalConns(),
+		MaxConns:          stat.MaxConns(),
+		AcquireCount:      stat.AcquireCount(),
+		EmptyAcquireCount: stat.EmptyAcquireCount(),
+	}
+}
@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"time"
+
+	"students-crud/internal/config"
+	"students-crud/migrations"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// ErrMigrationLocked миграции уже выполняет другой процесс, и блокировку не удалось получить вовремя
+var ErrMigrationLocked = errors.New("migrations are locked by another process")
+
+// migrationLockID ключ advisory-блокировки, под которой выполняются миграции.
+// Отличается от ключа внутренней блокировки golang-migrate, чтобы они не пересекались.
+const migrationLockID int64 = 0x73747564656e7473 // "students"
+
+// migrationLockRetry пауза между попытками взять блокировку
+const migrationLockRetry = 500 * time.Millisecond
+
+// Migrator применяет встроенные миграции. Все изменяющие схему операции выполняются
+// под advisory-блокировкой, поэтому одновременный запуск нескольких реплик безопасен.
+type Migrator struct {
+	m           *migrate.Migrate
+	db          *sql.DB
+	lockTimeout time.Duration
+	log         *slog.Logger
+}
+
+// NewMigrator подключается к базе для миграций. lockTimeout ограничивает ожидание
+// блокировки; 0 - ждать, пока не отменят контекст.
+func NewMigrator(cfg *config.Storage, lockTimeout time.Duration, log *slog.Logger) (*Migrator, error) {
+	const op = "storage.postgres.NewMigrator"
+
+	dsn, err := connString(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	configurePool(poolConfig, cfg)
+
+	// Долгие изменения схемы не должны прерываться statement_timeout приложения
+	connConfig := poolConfig.ConnConfig
+	delete(connConfig.RuntimeParams, "statement_timeout")
+
+	db := stdlib.OpenDB(*connConfig)
+
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "postgres", driver)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Migrator{m: m, db: db, lockTimeout: lockTimeout, log: log}, nil
+}
+
+// Close закрывает соединения мигратора
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	return errors.Join(sourceErr, dbErr, m.db.Close())
+}
+
+// Up применяет все новые миграции
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.run(ctx, "storage.postgres.MigrateUp", m.m.Up)
+}
+
+// Down откатывает steps последних миграций
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.run(ctx, "storage.postgres.MigrateDown", func() error {
+		return m.m.Steps(-steps)
+	})
+}
+
+// Goto переводит схему на указанную версию вверх или вниз
+func (m *Migrator) Goto(ctx context.Context, version uint) error {
+	return m.run(ctx, "storage.postgres.MigrateGoto", func() error {
+		return m.m.Migrate(version)
+	})
+}
+
+// Force записывает версию схемы без выполнения миграций и снимает признак dirty.
+// Нужна после ручного исправления неудавшейся миграции.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	return m.run(ctx, "storage.postgres.MigrateForce", func() error {
+		return m.m.Force(version)
+	})
+}
+
+// Version возвращает текущую версию схемы; 0 - миграции не применялись
+func (m *Migrator) Version() (version uint, dirty bool, err error) {
+	const op = "storage.postgres.MigrateVersion"
+
+	version, dirty, err = m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return version, dirty, nil
+}
+
+// run выполняет операцию под блокировкой. Отсутствие изменений ошибкой не считается.
+func (m *Migrator) run(ctx context.Context, op string, fn func() error) error {
+	if m.lockTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.lockTimeout)
+		defer cancel()
+	}
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer conn.Close()
+
+	err = m.lock(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		_, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", migrationLockID)
+		if err != nil {
+			m.log.Warn("failed to release migration lock", "error", err)
+		}
+	}()
+
+	before, _, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	after, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	m.log.Info("migrations finished", "from", before, "to", after, "dirty", dirty)
+	return nil
+}
+
+// lock ждет advisory-блокировку миграций, пока не истечет ctx
+func (m *Migrator) lock(ctx context.Context, conn *sql.Conn) error {
+	waiting := false
+	for {
+		var locked bool
+		err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", migrationLockID).Scan(&locked)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ErrMigrationLocked
+			}
+			return err
+		}
+		if locked {
+			return nil
+		}
+
+		if !waiting {
+			m.log.Info("waiting for migration lock held by another process")
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ErrMigrationLocked
+		case <-time.After(migrationLockRetry):
+		}
+	}
+}
+
+// LatestMigration возвращает версию последней встроенной миграции
+func LatestMigration() (uint, error) {
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		next, err := source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestLatestMigration(t *testing.T) {
+	version, err := LatestMigration()
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint(13), version)
+}
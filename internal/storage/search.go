@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"students-crud/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// searchSimilarityThreshold минимальное сходство слова запроса с частью имени или почты.
+// Ниже значения pg_trgm по умолчанию (0.6), чтобы находить имена с опечатками.
+const searchSimilarityThreshold = "0.4"
+
+// Маркеры совпадений в ts_headline. Управляющие символы не встречаются в данных,
+// поэтому после экранирования их можно безопасно заменить тегами.
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+// searchQuery находит студентов по префиксам слов (tsvector) или по сходству триграмм.
+// $1 - префиксный tsquery, $2 - исходная строка запроса.
+const searchQuery = `
+	WITH q AS (SELECT to_tsquery('simple', $1) AS tsq)
+	SELECT id, name, email, version,
+		ts_rank(search_vector, q.tsq) + greatest(word_similarity($2, name), word_similarity($2, email)) AS rank,
+		ts_headline('simple', name, q.tsq, $3),
+		ts_headline('simple', email, q.tsq, $3)
+	FROM students, q
+	WHERE deleted_at IS NULL AND (search_vector @@ q.tsq OR $2 <% name OR $2 <% email)
+	ORDER BY rank DESC, id
+	LIMIT $4 OFFSET $5`
+
+const searchCountQuery = `
+	SELECT COUNT(*) FROM students
+	WHERE deleted_at IS NULL AND (search_vector @@ to_tsquery('simple', $1) OR $2 <% name OR $2 <% email)`
+
+// Search ищет действующих студентов по имени и почте с учетом опечаток и неполных слов.
+// Результаты упорядочены по убыванию релевантности.
+func (s *Storage) Search(ctx context.Context, params models.SearchParams) (*models.SearchList, error) {
+	const op = "storage.postgres.Search"
+	ctx = withOperation(ctx, op)
+
+	tsquery := prefixQuery(params.Query)
+	headlineOptions := fmt.Sprintf("StartSel=%s, StopSel=%s, HighlightAll=true", highlightStart, highlightStop)
+
+	list := &models.SearchList{Results: []models.SearchResult{}}
+
+	err := s.readOnly(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, "SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)", searchSimilarityThreshold)
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRow(ctx, searchCountQuery, tsquery, params.Query).Scan(&list.Total)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, searchQuery, tsquery, params.Query, headlineOptions, params.Limit, params.Offset)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				result                      models.SearchResult
+				nameHeadline, emailHeadline string
+			)
+			err = rows.Scan(&result.ID, &result.Name, &result.Email, &result.Version, &result.Rank, &nameHeadline, &emailHeadline)
+			if err != nil {
+				return err
+			}
+
+			result.Highlights = map[string]string{}
+			if h, ok := highlight(nameHeadline); ok {
+				result.Highlights["name"] = h
+			}
+			if h, ok := highlight(emailHeadline); ok {
+				result.Highlights["email"] = h
+			}
+
+			list.Results = append(list.Results, result)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return list, nil
+}
+
+// prefixQuery превращает пользовательский ввод в tsquery, где каждое слово ищется как префикс:
+// "ив петр" -> "ив:* & петр:*". Все символы, кроме букв и цифр, считаются разделителями,
+// поэтому синтаксис tsquery из ввода не проходит.
+func prefixQuery(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
+
+// highlight экранирует фрагмент ts_headline и заменяет маркеры тегом <mark>.
+// Возвращает false, если совпадений в фрагменте нет.
+func highlight(headline string) (string, bool) {
+	if !strings.Contains(headline, highlightStart) {
+		return "", false
+	}
+
+	escaped := html.EscapeString(headline)
+	return strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>").Replace(escaped), true
+}
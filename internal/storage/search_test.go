@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestPrefixQuery(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{name: "Words", query: "Ив  Петр", expected: "ив:* & петр:*"},
+		{name: "Email", query: "ivanov@mail.ru", expected: "ivanov:* & mail:* & ru:*"},
+		{name: "Operators Stripped", query: "a & !b | c:*", expected: "a:* & b:* & c:*"},
+		{name: "Empty", query: " -- ", expected: ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, prefixQuery(testCase.query))
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	h, ok := highlight("\x02Ivan\x03 <script>")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "<mark>Ivan</mark> &lt;script&gt;", h)
+
+	_, ok = highlight("Ivan")
+	assert.Equal(t, false, ok)
+}
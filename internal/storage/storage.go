@@ -2,80 +2,157 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
+	"time"
 
+	"students-crud/internal/audit"
 	"students-crud/internal/config"
 	"students-crud/internal/models"
 
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/jackc/pgx/v5/stdlib"
 )
 
 type Storage struct {
 	pool *pgxpool.Pool
+	log  *slog.Logger
+
+	// db выполняет запросы: пул или транзакция WithTx, в которой работает копия хранилища
+	db dbtx
+	tx pgx.Tx
+
+	// Параметры транзакций WithTx по умолчанию
+	txStarter    txStarter
+	txIsolation  pgx.TxIsoLevel
+	txMaxRetries int
+
+	// schemaVersion версия последней встроенной миграции, которую ожидает код
+	schemaVersion uint
 }
 
-func New(cfg *config.Storage) (*Storage, error) {
+// New подключается к базе. Миграции не применяются: их выполняет Migrator.
+// tracers получают события всех запросов хранилища; операцию, к которой
+// относится запрос, возвращает Operation.
+func New(cfg *config.Storage, log *slog.Logger, tracers ...pgx.QueryTracer) (*Storage, error) {
 	const op = "storage.postgres.New"
 
-	pool, err := pgxpool.New(context.Background(), fmt.Sprintf("user=%s password=%s host=%s port=%s dbname=%s",
-		cfg.User,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.DB,
-	))
+	dsn, err := connString(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	err = pool.Ping(context.Background())
+	poolConfig, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	db := stdlib.OpenDB(*pool.Config().ConnConfig)
+	configurePool(poolConfig, cfg)
+
+	if len(tracers) > 0 {
+		poolConfig.ConnConfig.Tracer = multiTracer(tracers)
+	}
 
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	m, err := migrate.NewWithDatabaseInstance("file://migrations", "postgres", driver)
+	err = pool.Ping(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	err = m.Up()
-	if err != nil && err != migrate.ErrNoChange {
+	version, err := LatestMigration()
+	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return &Storage{pool: pool}, nil
+	connConfig := pool.Config().ConnConfig
+	log.Info("connected to database", "host", connConfig.Host, "db", connConfig.Database, "expected_schema_version", version)
+
+	return &Storage{
+		pool:          pool,
+		log:           log,
+		db:            pool,
+		txStarter:     pool,
+		txIsolation:   isoLevels[cfg.TxIsolation],
+		txMaxRetries:  cfg.TxMaxRetries,
+		schemaVersion: version,
+	}, nil
+}
+
+// Close закрывает пул соединений, дожидаясь возврата занятых соединений
+func (s *Storage) Close() {
+	s.pool.Close()
+	s.log.Info("database connections closed")
 }
 
 // Create создает нового студента
 func (s *Storage) Create(ctx context.Context, student *models.Student) (int, error) {
 	const op = "storage.postgres.Create"
+	ctx = withOperation(ctx, op)
 
 	var id int
-	err := s.pool.QueryRow(ctx, "INSERT INTO students (name, email) VALUES ($1, $2) RETURNING id", student.Name, student.Email).Scan(&id)
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		err := scanStudent(tx.QueryRow(ctx, `
+			INSERT INTO students (name, email, first_name, last_name, middle_name, birth_date, phone, enrollment_year, status, student_number)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE(NULLIF($9, ''), 'enrolled'), COALESCE(NULLIF($10, ''), next_student_number()))
+			RETURNING `+studentColumns,
+			student.Name, student.Email, student.FirstName, student.LastName, student.MiddleName,
+			student.BirthDate, student.Phone, student.EnrollmentYear, student.Status, student.StudentNumber,
+		), student)
+		if err != nil {
+			return err
+		}
+		id = student.ID
+
+		return writeAudit(ctx, tx, id, models.AuditCreate, nil, student)
+	})
 	if err != nil {
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return 0, wrapError(op, err)
 	}
 
 	return id, nil
 }
 
+// studentColumns колонки студента в порядке scanStudent
+const studentColumns = `id, name, email, first_name, last_name, middle_name, birth_date, phone, enrollment_year,
+	status, student_number, group_id, version, created_at, updated_at, deleted_at`
+
+func scanStudent(row pgx.Row, student *models.Student) error {
+	return row.Scan(&student.ID, &student.Name, &student.Email, &student.FirstName, &student.LastName, &student.MiddleName,
+		&student.BirthDate, &student.Phone, &student.EnrollmentYear, &student.Status, &student.StudentNumber,
+		&student.GroupID, &student.Version, &student.CreatedAt, &student.UpdatedAt, &student.DeletedAt)
+}
+
 // Read читает студента по ID
 func (s *Storage) Read(ctx context.Context, id int) (*models.Student, error) {
 	const op = "storage.postgres.Read"
+	ctx = withOperation(ctx, op)
 
 	student := &models.Student{}
-	err := s.pool.QueryRow(ctx, "SELECT id, name, email FROM students WHERE id=$1", id).Scan(&student.ID, &student.Name, &student.Email)
+	err := scanStudent(s.db.QueryRow(ctx, "SELECT "+studentColumns+" FROM students WHERE id=$1 AND deleted_at IS NULL", id), student)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return student, nil
+}
+
+// ReadForUpdate читает действующего студента и блокирует строку до конца транзакции.
+// Имеет смысл внутри WithTx: вне транзакции блокировка снимается сразу после чтения.
+func (s *Storage) ReadForUpdate(ctx context.Context, id int) (*models.Student, error) {
+	const op = "storage.postgres.ReadForUpdate"
+	ctx = withOperation(ctx, op)
+
+	student, err := lockStudent(ctx, s.db, id, false)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -83,26 +160,418 @@ func (s *Storage) Read(ctx context.Context, id int) (*models.Student, error) {
 	return student, nil
 }
 
-// Update обновляет информацию о студенте
+// sortColumns сопоставляет поле сортировки с колонкой таблицы
+var sortColumns = map[string]string{
+	models.SortByID:    "id",
+	models.SortByName:  "name",
+	models.SortByEmail: "email",
+}
+
+// List возвращает страницу студентов с учетом фильтров, сортировки и пагинации
+func (s *Storage) List(ctx context.Context, params models.ListParams) (*models.StudentList, error) {
+	const op = "storage.postgres.List"
+	ctx = withOperation(ctx, op)
+
+	sortBy := params.SortBy
+	column, ok := sortColumns[sortBy]
+	if !ok {
+		sortBy, column = models.SortByID, "id"
+	}
+
+	direction, compare := "ASC", ">"
+	if params.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	var (
+		conditions []string
+		args       []any
+	)
+
+	if !params.WithDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
+	if params.Name != "" {
+		args = append(args, "%"+escapeLike(params.Name)+"%")
+		conditions = append(conditions, fmt.Sprintf("name ILIKE $%d", len(args)))
+	}
+
+	if params.EmailDomain != "" {
+		args = append(args, "%@"+escapeLike(params.EmailDomain))
+		conditions = append(conditions, fmt.Sprintf("email ILIKE $%d", len(args)))
+	}
+
+	var total int
+	err := s.db.QueryRow(ctx, "SELECT COUNT(*) FROM students"+whereClause(conditions), args...).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if params.Cursor != "" {
+		c, err := decodeCursor(params.Cursor)
+		if err != nil || !c.matches(sortBy, params.Desc) {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidCursor)
+		}
+
+		if column == "id" {
+			args = append(args, c.ID)
+			conditions = append(conditions, fmt.Sprintf("id %s $%d", compare, len(args)))
+		} else {
+			args = append(args, c.Value, c.ID)
+			conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d)", column, compare, len(args)-1, len(args)))
+		}
+	}
+
+	query := "SELECT " + studentColumns + " FROM students" + whereClause(conditions)
+	if column == "id" {
+		query += fmt.Sprintf(" ORDER BY id %s", direction)
+	} else {
+		query += fmt.Sprintf(" ORDER BY %s %s, id %s", column, direction, direction)
+	}
+
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	args = append(args, params.Limit+1, params.Offset)
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	list := &models.StudentList{Students: []models.Student{}, Total: total}
+	for rows.Next() {
+		var student models.Student
+		err = scanStudent(rows, &student)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		list.Students = append(list.Students, student)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(list.Students) > params.Limit {
+		list.Students = list.Students[:params.Limit]
+
+		last := list.Students[len(list.Students)-1]
+		next := cursor{SortBy: sortBy, Desc: params.Desc, ID: last.ID}
+		switch column {
+		case "name":
+			next.Value = last.Name
+		case "email":
+			next.Value = last.Email
+		}
+		list.NextCursor = encodeCursor(next)
+	}
+
+	return list, nil
+}
+
+// Export последовательно передает всех студентов в fn в порядке ID, не загружая таблицу в память.
+// Ошибка из fn прерывает выгрузку и возвращается вызывающему.
+func (s *Storage) Export(ctx context.Context, fn func(student models.Student) error) error {
+	const op = "storage.postgres.Export"
+	ctx = withOperation(ctx, op)
+
+	rows, err := s.db.Query(ctx, "SELECT "+studentColumns+" FROM students WHERE deleted_at IS NULL ORDER BY id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var student models.Student
+		err = scanStudent(rows, &student)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = fn(student)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// whereClause собирает условия в секцию WHERE
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Update обновляет информацию о студенте.
+// Если student.Version задан, обновление выполняется только при совпадении версии;
+// после успешного обновления student.Version содержит новую версию.
 func (s *Storage) Update(ctx context.Context, student *models.Student) error {
 	const op = "storage.postgres.Update"
+	ctx = withOperation(ctx, op)
+
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		before, err := lockStudent(ctx, tx, student.ID, false)
+		if err != nil {
+			return err
+		}
+
+		if student.Version != 0 && student.Version != before.Version {
+			return ErrVersionConflict
+		}
+
+		// Пустой номер не изменяется; статус меняется только переходом, группа - переводом
+		err = scanStudent(tx.QueryRow(ctx, `
+			UPDATE students SET name=$1, email=$2, first_name=$3, last_name=$4, middle_name=$5, birth_date=$6, phone=$7,
+				enrollment_year=$8, student_number=COALESCE(NULLIF($9, ''), student_number), version=version+1
+			WHERE id=$10 RETURNING `+studentColumns,
+			student.Name, student.Email, student.FirstName, student.LastName, student.MiddleName, student.BirthDate, student.Phone,
+			student.EnrollmentYear, student.StudentNumber, student.ID,
+		), student)
+		if err != nil {
+			return err
+		}
 
-	_, err := s.pool.Exec(ctx, "UPDATE students SET name=$1, email=$2 WHERE id=$3", student.Name, student.Email, student.ID)
+		return writeAudit(ctx, tx, student.ID, models.AuditUpdate, before, student)
+	})
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return wrapError(op, err)
 	}
 
 	return nil
 }
 
-// Delete удаляет студента по ID
-func (s *Storage) Delete(ctx context.Context, id int) error {
+// Patch обновляет только переданные поля студента и возвращает итоговую запись
+func (s *Storage) Patch(ctx context.Context, id int, patch models.StudentPatch) (*models.Student, error) {
+	const op = "storage.postgres.Patch"
+	ctx = withOperation(ctx, op)
+
+	if patch.Empty() {
+		student, err := s.Read(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if patch.Version != 0 && patch.Version != student.Version {
+			return nil, fmt.Errorf("%s: %w", op, ErrVersionConflict)
+		}
+
+		return student, nil
+	}
+
+	var (
+		sets []string
+		args []any
+	)
+
+	if patch.Name != nil {
+		args = append(args, *patch.Name)
+		sets = append(sets, fmt.Sprintf("name=$%d", len(args)))
+	}
+
+	if patch.Email != nil {
+		args = append(args, *patch.Email)
+		sets = append(sets, fmt.Sprintf("email=$%d", len(args)))
+	}
+
+	for _, field := range []struct {
+		column string
+		value  any
+		set    bool
+	}{
+		{"first_name", patch.FirstName, patch.FirstName != nil},
+		{"last_name", patch.LastName, patch.LastName != nil},
+		{"middle_name", patch.MiddleName, patch.MiddleName != nil},
+		{"birth_date", patch.BirthDate, patch.BirthDate != nil},
+		{"phone", patch.Phone, patch.Phone != nil},
+		{"enrollment_year", patch.EnrollmentYear, patch.EnrollmentYear != nil},
+	} {
+		if field.set {
+			args = append(args, field.value)
+			sets = append(sets, fmt.Sprintf("%s=$%d", field.column, len(args)))
+		}
+	}
+
+	for _, field := range patch.Clear {
+		switch field {
+		case models.FieldBirthDate:
+			sets = append(sets, "birth_date=NULL")
+		case models.FieldEnrollmentYear:
+			sets = append(sets, "enrollment_year=NULL")
+		}
+	}
+
+	args = append(args, id)
+	query := fmt.Sprintf("UPDATE students SET %s, version=version+1 WHERE id=$%d RETURNING %s", strings.Join(sets, ", "), len(args), studentColumns)
+
+	student := &models.Student{}
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		before, err := lockStudent(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		if patch.Version != 0 && patch.Version != before.Version {
+			return ErrVersionConflict
+		}
+
+		err = scanStudent(tx.QueryRow(ctx, query, args...), student)
+		if err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, id, models.AuditUpdate, before, student)
+	})
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	return student, nil
+}
+
+// Delete помечает студента удаленным. Если version не равен 0, удаление выполняется
+// только при совпадении версии записи. Запись остается в таблице до Purge.
+func (s *Storage) Delete(ctx context.Context, id int, version int) error {
 	const op = "storage.postgres.Delete"
+	ctx = withOperation(ctx, op)
+
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		before, err := lockStudent(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		if version != 0 && version != before.Version {
+			return ErrVersionConflict
+		}
 
-	_, err := s.pool.Exec(ctx, "DELETE FROM students WHERE id=$1", id)
+		after := *before
+		err = tx.QueryRow(ctx, "UPDATE students SET deleted_at=now(), version=version+1 WHERE id=$1 RETURNING version, deleted_at", id).
+			Scan(&after.Version, &after.DeletedAt)
+		if err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, id, models.AuditDelete, before, &after)
+	})
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return wrapError(op, err)
+	}
+
+	return nil
+}
+
+// Restore возвращает мягко удаленного студента. Записи на курсы у удаленного студента
+// сохраняются, но места не занимают, поэтому восстановление не проходит, если
+// какой-то из его курсов за это время заполнился.
+func (s *Storage) Restore(ctx context.Context, id int) (*models.Student, error) {
+	const op = "storage.postgres.Restore"
+	ctx = withOperation(ctx, op)
+
+	student := &models.Student{}
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		before, err := lockStudent(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+
+		err = checkSeats(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		err = scanStudent(tx.QueryRow(ctx, "UPDATE students SET deleted_at=NULL, version=version+1 WHERE id=$1 RETURNING "+studentColumns, id), student)
+		if err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, id, models.AuditRestore, before, student)
+	})
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	return student, nil
+}
+
+// Purge безвозвратно удаляет студента, в том числе мягко удаленного
+func (s *Storage) Purge(ctx context.Context, id int) error {
+	const op = "storage.postgres.Purge"
+	ctx = withOperation(ctx, op)
+
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		before := &models.Student{}
+		err := tx.QueryRow(ctx, "DELETE FROM students WHERE id=$1 RETURNING id, name, email, version, deleted_at", id).
+			Scan(&before.ID, &before.Name, &before.Email, &before.Version, &before.DeletedAt)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		return writeAudit(ctx, tx, id, models.AuditPurge, before, nil)
+	})
+	if err != nil {
+		return wrapError(op, err)
 	}
 
 	return nil
 }
+
+// lockStudent читает студента с блокировкой строки до конца транзакции.
+// deleted выбирает, среди каких записей искать: мягко удаленных или действующих.
+func lockStudent(ctx context.Context, tx dbtx, id int, deleted bool) (*models.Student, error) {
+	query := "SELECT " + studentColumns + " FROM students WHERE id=$1 AND deleted_at IS NULL FOR UPDATE"
+	if deleted {
+		query = "SELECT " + studentColumns + " FROM students WHERE id=$1 AND deleted_at IS NOT NULL FOR UPDATE"
+	}
+
+	student := &models.Student{}
+	err := scanStudent(tx.QueryRow(ctx, query, id), student)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return student, nil
+}
+
+// PurgeDeleted безвозвратно удаляет студентов, мягко удаленных раньше before.
+// Возвращает количество удаленных записей.
+func (s *Storage) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	const op = "storage.postgres.PurgeDeleted"
+	ctx = withOperation(ctx, op)
+
+	// Удаление и запись в журнал выполняются одним запросом
+	tag, err := s.db.Exec(ctx, `WITH purged AS (
+	DELETE FROM students WHERE deleted_at < $1 RETURNING id, name, email, deleted_at
+)
+INSERT INTO audit_events (student_id, action, actor, request_id, changes)
+SELECT id, $2, $3, $4, jsonb_build_object(
+	'name', jsonb_build_object('before', name, 'after', NULL),
+	'email', jsonb_build_object('before', email, 'after', NULL),
+	'deleted_at', jsonb_build_object('before', deleted_at, 'after', NULL)
+) FROM purged`, before, models.AuditPurge, audit.Actor(ctx), audit.RequestID(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
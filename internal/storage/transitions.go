@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"students-crud/internal/audit"
+	"students-crud/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Transition переводит студента в статус input.To и записывает переход в историю и журнал аудита.
+// Допустимость перехода не проверяется: это делает сервис под блокировкой строки в той же транзакции.
+func (s *Storage) Transition(ctx context.Context, studentID int, input models.TransitionInput) (*models.StatusTransition, error) {
+	const op = "storage.postgres.Transition"
+	ctx = withOperation(ctx, op)
+
+	transition := &models.StatusTransition{
+		StudentID: studentID,
+		To:        input.To,
+		Reason:    input.Reason,
+		Actor:     audit.Actor(ctx),
+		RequestID: audit.RequestID(ctx),
+	}
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		before, err := lockStudent(ctx, tx, studentID, false)
+		if err != nil {
+			return err
+		}
+
+		transition.From = before.Status
+
+		after := &models.Student{}
+		err = scanStudent(tx.QueryRow(ctx, "UPDATE students SET status=$1, version=version+1 WHERE id=$2 RETURNING "+studentColumns,
+			input.To, studentID,
+		), after)
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRow(ctx, `INSERT INTO status_transitions (student_id, from_status, to_status, reason, actor, request_id)
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
+			studentID, transition.From, transition.To, transition.Reason, transition.Actor, transition.RequestID,
+		).Scan(&transition.ID, &transition.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, studentID, models.AuditTransition, before, after)
+	})
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	return transition, nil
+}
+
+// StatusHistory возвращает историю смены статуса студента от новых переходов к старым
+func (s *Storage) StatusHistory(ctx context.Context, studentID, limit, offset int) (*models.StatusHistory, error) {
+	const op = "storage.postgres.StatusHistory"
+	ctx = withOperation(ctx, op)
+
+	history := &models.StatusHistory{Transitions: []models.StatusTransition{}}
+
+	err := s.db.QueryRow(ctx, "SELECT COUNT(*) FROM status_transitions WHERE student_id=$1", studentID).Scan(&history.Total)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := s.db.Query(ctx, `SELECT id, student_id, from_status, to_status, reason, actor, request_id, created_at
+FROM status_transitions WHERE student_id=$1 ORDER BY id DESC LIMIT $2 OFFSET $3`, studentID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transition models.StatusTransition
+		err = rows.Scan(&transition.ID, &transition.StudentID, &transition.From, &transition.To, &transition.Reason,
+			&transition.Actor, &transition.RequestID, &transition.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		history.Transitions = append(history.Transitions, transition)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return history, nil
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"students-crud/internal/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// dbtx общая часть пула и транзакции. Методы хранилища выполняют запросы через нее,
+// поэтому внутри WithTx они работают в транзакции, а их собственные транзакции
+// становятся точками сохранения.
+type dbtx interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// txStarter открывает транзакции верхнего уровня: пул, а в тестах - подмена
+type txStarter interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// Задержка перед повтором транзакции растет вдвое с каждой попыткой до txRetryMaxDelay
+const (
+	txRetryBaseDelay = 10 * time.Millisecond
+	txRetryMaxDelay  = time.Second
+)
+
+// isoLevels уровни изоляции из конфигурации
+var isoLevels = map[string]pgx.TxIsoLevel{
+	config.TxReadCommitted:  pgx.ReadCommitted,
+	config.TxRepeatableRead: pgx.RepeatableRead,
+	config.TxSerializable:   pgx.Serializable,
+}
+
+// TxOptions параметры транзакции WithTxOptions
+type TxOptions struct {
+	Isolation pgx.TxIsoLevel // пусто - уровень из конфигурации
+	ReadOnly  bool
+}
+
+// WithTx выполняет fn в транзакции с параметрами по умолчанию, см. WithTxOptions
+func (s *Storage) WithTx(ctx context.Context, fn func(tx *Storage) error) error {
+	return s.WithTxOptions(ctx, TxOptions{}, fn)
+}
+
+// WithTxOptions выполняет fn в транзакции: все вызовы tx внутри fn видят одни данные и
+// фиксируются вместе. Если fn возвращает ошибку, транзакция откатывается, а ошибка
+// возвращается как есть. При конфликте сериализации или взаимной блокировке транзакция
+// повторяется целиком до TxMaxRetries раз, поэтому fn не должна иметь побочных эффектов
+// вне базы. Вложенный вызов создает точку сохранения: его параметры не применяются,
+// а повторяется только внешняя транзакция.
+func (s *Storage) WithTxOptions(ctx context.Context, opts TxOptions, fn func(tx *Storage) error) error {
+	const op = "storage.postgres.WithTx"
+
+	var fnErr error
+	run := func(tx pgx.Tx) error {
+		fnErr = fn(s.withTx(tx))
+		return fnErr
+	}
+
+	if s.tx != nil {
+		err := pgx.BeginFunc(ctx, s.tx, run)
+		if err != nil && fnErr == nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return err
+	}
+
+	txOptions := pgx.TxOptions{IsoLevel: opts.Isolation}
+	if txOptions.IsoLevel == "" {
+		txOptions.IsoLevel = s.txIsolation
+	}
+	if opts.ReadOnly {
+		txOptions.AccessMode = pgx.ReadOnly
+	}
+
+	for attempt := 0; ; attempt++ {
+		fnErr = nil
+		err := pgx.BeginTxFunc(ctx, s.txStarter, txOptions, run)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= s.txMaxRetries || !retryable(err) {
+			if fnErr != nil {
+				return fnErr
+			}
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		delay := retryDelay(attempt)
+		s.log.DebugContext(ctx, "retrying transaction", "attempt", attempt+1, "delay", delay, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", op, errors.Join(err, ctx.Err()))
+		case <-time.After(delay):
+		}
+	}
+}
+
+// withTx возвращает копию хранилища, выполняющую запросы в транзакции tx
+func (s *Storage) withTx(tx pgx.Tx) *Storage {
+	clone := *s
+	clone.db = tx
+	clone.tx = tx
+	return &clone
+}
+
+// readOnly выполняет fn в транзакции только для чтения, а внутри WithTx - в точке сохранения
+func (s *Storage) readOnly(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	if s.tx != nil {
+		return pgx.BeginFunc(ctx, s.tx, fn)
+	}
+
+	return pgx.BeginTxFunc(ctx, s.txStarter, pgx.TxOptions{AccessMode: pgx.ReadOnly}, fn)
+}
+
+// retryable сообщает, что транзакцию можно повторить целиком
+func retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+}
+
+// retryDelay задержка перед повтором с номером attempt (с нуля): экспоненциальная,
+// со случайным разбросом в пределах половины, чтобы конфликтующие транзакции разошлись
+func retryDelay(attempt int) time.Duration {
+	delay := txRetryMaxDelay
+	if attempt < 10 {
+		delay = min(txRetryBaseDelay<<attempt, txRetryMaxDelay)
+	}
+
+	return delay/2 + rand.N(delay/2+1)
+}
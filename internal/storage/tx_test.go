@@ -0,0 +1,284 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestRetryable(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "Serialization Failure", err: &pgconn.PgError{Code: pgSerializationFailure}, expected: true},
+		{name: "Deadlock", err: &pgconn.PgError{Code: pgDeadlockDetected}, expected: true},
+		{name: "Wrapped", err: wrapError("storage.postgres.Patch", &pgconn.PgError{Code: pgSerializationFailure}), expected: true},
+		{name: "Unique Violation", err: &pgconn.PgError{Code: pgUniqueViolation}, expected: false},
+		{name: "Domain Error", err: fmt.Errorf("op: %w", ErrNotFound), expected: false},
+		{name: "Other", err: errors.New("connection reset"), expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, retryable(testCase.err))
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	testCases := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{attempt: 0, min: 5 * time.Millisecond, max: 10 * time.Millisecond},
+		{attempt: 2, min: 20 * time.Millisecond, max: 40 * time.Millisecond},
+		{attempt: 7, min: 500 * time.Millisecond, max: time.Second},
+		{attempt: 100, min: 500 * time.Millisecond, max: time.Second},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(fmt.Sprint(testCase.attempt), func(t *testing.T) {
+			for range 100 {
+				delay := retryDelay(testCase.attempt)
+				assert.Equal(t, true, delay >= testCase.min && delay <= testCase.max)
+			}
+		})
+	}
+}
+
+// fakeConn открывает транзакции без базы и записывает управляющие команды в журнал
+type fakeConn struct {
+	log        []string
+	options    []pgx.TxOptions
+	commitErrs []error // ошибки COMMIT по очереди попыток
+}
+
+func (c *fakeConn) BeginTx(_ context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	c.options = append(c.options, txOptions)
+	c.log = append(c.log, "begin")
+
+	tx := &fakeTx{conn: c}
+	if len(c.commitErrs) > 0 {
+		tx.commitErr, c.commitErrs = c.commitErrs[0], c.commitErrs[1:]
+	}
+
+	return tx, nil
+}
+
+// fakeTx транзакция или точка сохранения fakeConn; остальные методы pgx.Tx не вызываются
+type fakeTx struct {
+	pgx.Tx
+	conn      *fakeConn
+	savepoint bool
+	closed    bool
+	commitErr error
+}
+
+func (tx *fakeTx) Begin(context.Context) (pgx.Tx, error) {
+	tx.conn.log = append(tx.conn.log, "savepoint")
+	return &fakeTx{conn: tx.conn, savepoint: true}, nil
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	if tx.closed {
+		return pgx.ErrTxClosed
+	}
+	tx.closed = true
+
+	switch {
+	case tx.savepoint:
+		tx.conn.log = append(tx.conn.log, "release savepoint")
+	case tx.commitErr != nil:
+		tx.conn.log = append(tx.conn.log, "commit failed")
+		return tx.commitErr
+	default:
+		tx.conn.log = append(tx.conn.log, "commit")
+	}
+
+	return nil
+}
+
+func (tx *fakeTx) Rollback(context.Context) error {
+	if tx.closed {
+		return pgx.ErrTxClosed
+	}
+	tx.closed = true
+
+	if tx.savepoint {
+		tx.conn.log = append(tx.conn.log, "rollback to savepoint")
+	} else {
+		tx.conn.log = append(tx.conn.log, "rollback")
+	}
+
+	return nil
+}
+
+func newTxStorage(conn *fakeConn) *Storage {
+	return &Storage{
+		log:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+		txStarter:    conn,
+		txIsolation:  pgx.ReadCommitted,
+		txMaxRetries: 2,
+	}
+}
+
+func TestWithTxOptions(t *testing.T) {
+	serializationFailure := &pgconn.PgError{Code: pgSerializationFailure}
+	deadlock := &pgconn.PgError{Code: pgDeadlockDetected}
+	errNotFound := fmt.Errorf("storage.postgres.Read: %w", ErrNotFound)
+
+	testCases := []struct {
+		name        string
+		commitErrs  []error
+		fn          func(attempt int, tx *Storage) error
+		expectedErr error
+		expectedRun int
+		expectedLog []string
+	}{
+		{
+			name:        "Commit",
+			fn:          func(int, *Storage) error { return nil },
+			expectedRun: 1,
+			expectedLog: []string{"begin", "commit"},
+		},
+		{
+			name:        "Rollback On Error",
+			fn:          func(int, *Storage) error { return errNotFound },
+			expectedErr: errNotFound,
+			expectedRun: 1,
+			expectedLog: []string{"begin", "rollback"},
+		},
+		{
+			name: "Retry On Serialization Failure",
+			fn: func(attempt int, _ *Storage) error {
+				if attempt == 1 {
+					return fmt.Errorf("storage.postgres.Patch: %w", serializationFailure)
+				}
+				return nil
+			},
+			expectedRun: 2,
+			expectedLog: []string{"begin", "rollback", "begin", "commit"},
+		},
+		{
+			name:        "Retry On Deadlock At Commit",
+			commitErrs:  []error{deadlock},
+			fn:          func(int, *Storage) error { return nil },
+			expectedRun: 2,
+			expectedLog: []string{"begin", "commit failed", "begin", "commit"},
+		},
+		{
+			name:        "Retries Exhausted",
+			fn:          func(int, *Storage) error { return serializationFailure },
+			expectedErr: serializationFailure,
+			expectedRun: 3,
+			expectedLog: []string{"begin", "rollback", "begin", "rollback", "begin", "rollback"},
+		},
+		{
+			name: "Nested Call Is Savepoint",
+			fn: func(_ int, tx *Storage) error {
+				return tx.WithTxOptions(context.Background(), TxOptions{Isolation: pgx.Serializable}, func(inner *Storage) error {
+					assert.Equal(t, true, inner.tx.(*fakeTx).savepoint)
+					return nil
+				})
+			},
+			expectedRun: 1,
+			expectedLog: []string{"begin", "savepoint", "release savepoint", "commit"},
+		},
+		{
+			name: "Nested Error Rolls Back Savepoint Only",
+			fn: func(_ int, tx *Storage) error {
+				err := tx.WithTx(context.Background(), func(*Storage) error { return errNotFound })
+				assert.Equal(t, errNotFound, err)
+				return nil
+			},
+			expectedRun: 1,
+			expectedLog: []string{"begin", "savepoint", "rollback to savepoint", "commit"},
+		},
+		{
+			name: "Nested Conflict Retries Outer Transaction",
+			fn: func(attempt int, tx *Storage) error {
+				return tx.WithTx(context.Background(), func(*Storage) error {
+					if attempt == 1 {
+						return deadlock
+					}
+					return nil
+				})
+			},
+			expectedRun: 2,
+			expectedLog: []string{
+				"begin", "savepoint", "rollback to savepoint", "rollback",
+				"begin", "savepoint", "release savepoint", "commit",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			conn := &fakeConn{commitErrs: testCase.commitErrs}
+			s := newTxStorage(conn)
+
+			run := 0
+			err := s.WithTx(context.Background(), func(tx *Storage) error {
+				run++
+				assert.NotEqual(t, nil, tx.tx)
+				assert.Equal(t, tx.tx, tx.db)
+				return testCase.fn(run, tx)
+			})
+
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedRun, run)
+			assert.Equal(t, testCase.expectedLog, conn.log)
+		})
+	}
+}
+
+func TestWithTxOptions_Options(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     TxOptions
+		expected pgx.TxOptions
+	}{
+		{name: "Configured Isolation", expected: pgx.TxOptions{IsoLevel: pgx.ReadCommitted}},
+		{name: "Serializable", opts: TxOptions{Isolation: pgx.Serializable}, expected: pgx.TxOptions{IsoLevel: pgx.Serializable}},
+		{
+			name:     "Read Only",
+			opts:     TxOptions{Isolation: pgx.RepeatableRead, ReadOnly: true},
+			expected: pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			conn := &fakeConn{}
+
+			err := newTxStorage(conn).WithTxOptions(context.Background(), testCase.opts, func(*Storage) error { return nil })
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, []pgx.TxOptions{testCase.expected}, conn.options)
+		})
+	}
+}
+
+func TestWithTxOptions_Canceled(t *testing.T) {
+	conn := &fakeConn{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := newTxStorage(conn).WithTx(ctx, func(*Storage) error {
+		cancel()
+		return &pgconn.PgError{Code: pgSerializationFailure}
+	})
+
+	assert.Equal(t, true, errors.Is(err, context.Canceled))
+	assert.Equal(t, true, retryable(err))
+	assert.Equal(t, []string{"begin", "rollback"}, conn.log)
+}
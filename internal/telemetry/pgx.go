@@ -0,0 +1,82 @@
+package telemetry
+
+import (
+	"context"
+
+	"students-crud/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultSpanName имя спана запроса вне операций хранилища
+const defaultSpanName = "postgres.query"
+
+// queryTracer открывает дочерний спан на каждый запрос к базе
+type queryTracer struct {
+	tracer trace.Tracer
+}
+
+// QueryTracer возвращает трассировщик pgx для передачи в storage.New.
+// Спан называется по операции хранилища, например "storage.postgres.Create".
+func QueryTracer(tp trace.TracerProvider) pgx.QueryTracer {
+	return queryTracer{tracer: tp.Tracer(instrumentationName)}
+}
+
+func (t queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = t.start(ctx, semconv.DBQueryText(data.SQL))
+	return ctx
+}
+
+func (t queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err == nil {
+		span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	}
+	end(span, data.Err)
+}
+
+func (t queryTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	size := 0
+	if data.Batch != nil {
+		size = data.Batch.Len()
+	}
+
+	ctx, _ = t.start(ctx, attribute.Int("db.batch.size", size))
+	return ctx
+}
+
+// TraceBatchQuery отмечает запросы пакета событиями, не создавая спан на каждый
+func (t queryTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil {
+		span.RecordError(data.Err, trace.WithAttributes(semconv.DBQueryText(data.SQL)))
+	}
+}
+
+func (t queryTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	end(trace.SpanFromContext(ctx), data.Err)
+}
+
+func (t queryTracer) start(ctx context.Context, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	name := storage.Operation(ctx)
+	if name == "" {
+		name = defaultSpanName
+	}
+
+	return t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attrs, semconv.DBSystemPostgreSQL)...),
+	)
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,93 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"students-crud/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName имя библиотеки инструментирования в создаваемых спанах
+const instrumentationName = "students-crud"
+
+// Propagator передает контекст трассировки в заголовках W3C traceparent/tracestate и baggage
+var Propagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+// NewTracerProvider создает провайдер трассировок с экспортером из конфигурации
+// и делает его глобальным. Провайдер нужно остановить через Shutdown, чтобы
+// отправить накопленные спаны.
+func NewTracerProvider(ctx context.Context, cfg *config.Tracing, version string) (*sdktrace.TracerProvider, error) {
+	const op = "telemetry.NewTracerProvider"
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		// Решение о выборке входящей трассировки принимает вызывающий сервис
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}
+
+	switch cfg.Exporter {
+	case config.TracingExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	case config.TracingExporterOTLP:
+		var clientOpts []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpoint(cfg.OTLPEndpoint))
+		}
+		if cfg.OTLPInsecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err := otlptracehttp.New(ctx, clientOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	case config.TracingExporterNone:
+		// Спаны создаются и распространяются, но никуда не отправляются
+	default:
+		return nil, fmt.Errorf("%s: unknown exporter %q", op, cfg.Exporter)
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(Propagator)
+
+	return tp, nil
+}
+
+// Middleware открывает спан на каждый запрос с именем маршрута и продолжает
+// трассировку из заголовка traceparent
+func Middleware(serviceName string, tp trace.TracerProvider) gin.HandlerFunc {
+	return otelgin.Middleware(serviceName,
+		otelgin.WithTracerProvider(tp),
+		otelgin.WithPropagators(Propagator),
+	)
+}
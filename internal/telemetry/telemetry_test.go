@@ -0,0 +1,86 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"students-crud/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newRecorder() (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	recorder := tracetest.NewSpanRecorder()
+	return recorder, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+}
+
+func TestMiddleware_QuerySpans(t *testing.T) {
+	recorder, tp := newRecorder()
+	tracer := QueryTracer(tp)
+
+	r := gin.New()
+	r.Use(Middleware("students-crud", tp))
+	r.GET("/students/:id", func(ctx *gin.Context) {
+		c := tracer.TraceQueryStart(ctx.Request.Context(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+		tracer.TraceQueryEnd(c, nil, pgx.TraceQueryEndData{Err: errors.New("connection reset")})
+		ctx.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/students/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	assert.Equal(t, 2, len(spans))
+
+	query, handler := spans[0], spans[1]
+
+	// Спан обработчика продолжает входящую трассировку
+	assert.Equal(t, "/students/:id", handler.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", handler.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", handler.Parent().SpanID().String())
+
+	// Спан запроса вложен в спан обработчика и несет ошибку
+	assert.Equal(t, defaultSpanName, query.Name())
+	assert.Equal(t, trace.SpanKindClient, query.SpanKind())
+	assert.Equal(t, handler.SpanContext().SpanID(), query.Parent().SpanID())
+	assert.Equal(t, codes.Error, query.Status().Code)
+}
+
+func TestQueryTracer_Batch(t *testing.T) {
+	recorder, tp := newRecorder()
+	tracer := QueryTracer(tp).(pgx.BatchTracer)
+
+	batch := &pgx.Batch{}
+	batch.Queue("SELECT 1")
+	batch.Queue("SELECT 2")
+
+	ctx := tracer.TraceBatchStart(context.Background(), nil, pgx.TraceBatchStartData{Batch: batch})
+	tracer.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "SELECT 1"})
+	tracer.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "SELECT 2"})
+	tracer.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{})
+
+	spans := recorder.Ended()
+	assert.Equal(t, 1, len(spans))
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+}
+
+func TestNewTracerProvider(t *testing.T) {
+	for _, exporter := range []string{config.TracingExporterNone, config.TracingExporterStdout, config.TracingExporterOTLP} {
+		tp, err := NewTracerProvider(context.Background(), &config.Tracing{Exporter: exporter, ServiceName: "test", SampleRatio: 1}, "dev")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, nil, tp.Shutdown(context.Background()))
+	}
+
+	_, err := NewTracerProvider(context.Background(), &config.Tracing{Exporter: "zipkin"}, "dev")
+	assert.NotEqual(t, nil, err)
+}
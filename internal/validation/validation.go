@@ -0,0 +1,210 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"students-crud/internal/models"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError описывает нарушение правила валидации для одного поля
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Errors список всех ошибок валидации структуры
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Field+" "+fe.Message)
+	}
+
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// В ошибках используем имена полей из json-тегов, как их видит клиент
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	_ = v.RegisterValidation("rfc5322", isRFC5322Email)
+	_ = v.RegisterValidation("phone", isPhone)
+	_ = v.RegisterValidation("student_number", isStudentNumber)
+	_ = v.RegisterValidation("past", isPast)
+
+	// Дата проверяется как время, чтобы к ней применялись правила для time.Time
+	v.RegisterCustomTypeFunc(func(field reflect.Value) any {
+		return field.Interface().(models.Date).Time
+	}, models.Date{})
+
+	return v
+}
+
+var (
+	phonePattern         = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+	studentNumberPattern = regexp.MustCompile(`^[0-9]{4}-[0-9]{6,11}$`)
+)
+
+// isPhone проверяет номер телефона в формате E.164; пустое значение допустимо
+func isPhone(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	return value == "" || phonePattern.MatchString(value)
+}
+
+// isStudentNumber проверяет формат номера студенческого билета; пустое значение означает,
+// что номер будет выдан автоматически
+func isStudentNumber(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	return value == "" || studentNumberPattern.MatchString(value)
+}
+
+// isPast проверяет, что дата уже наступила
+func isPast(fl validator.FieldLevel) bool {
+	t, ok := fl.Field().Interface().(time.Time)
+	return ok && t.Before(time.Now())
+}
+
+// isRFC5322Email проверяет, что значение является голым адресом по RFC 5322 без отображаемого имени
+func isRFC5322Email(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+
+	addr, err := mail.ParseAddress(value)
+	if err != nil {
+		return false
+	}
+
+	return addr.Name == "" && addr.Address == value
+}
+
+// Validate нормализует структуру по тегам normalize и проверяет ее по тегам validate.
+// Возвращает Errors со всеми нарушенными правилами.
+func Validate(v any) error {
+	Normalize(v)
+
+	err := validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+
+	result := make(Errors, 0, len(verrs))
+	for _, fe := range verrs {
+		result = append(result, FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: message(fe),
+		})
+	}
+
+	return result
+}
+
+// message формирует человекочитаемое описание ошибки
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "max":
+		if fe.Kind() != reflect.String {
+			return fmt.Sprintf("must be at most %s", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s characters", fe.Param())
+	case "min":
+		if fe.Kind() != reflect.String {
+			return fmt.Sprintf("must be at least %s", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s characters", fe.Param())
+	case "email", "rfc5322":
+		return "must be a valid email address"
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	case "phone":
+		return "must be a phone number in E.164 format, e.g. +79991234567"
+	case "student_number":
+		return "must be a student number like 2024-000042"
+	case "past":
+		return "must be in the past"
+	default:
+		return fmt.Sprintf("failed on rule %q", fe.Tag())
+	}
+}
+
+// Normalize применяет к строковым полям структуры (и указателям на строки)
+// преобразования из тега normalize: trim - обрезает пробелы по краям,
+// lower - приводит к нижнему регистру
+func Normalize(v any) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return
+	}
+
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rv.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		if field.Kind() == reflect.Struct {
+			Normalize(field.Addr().Interface())
+			continue
+		}
+
+		tag := rt.Field(i).Tag.Get("normalize")
+		if tag == "" {
+			continue
+		}
+
+		// Необязательные поля частичных обновлений представлены указателями
+		if field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+
+		if field.Kind() != reflect.String {
+			continue
+		}
+
+		value := field.String()
+		for _, op := range strings.Split(tag, ",") {
+			switch op {
+			case "trim":
+				value = strings.TrimSpace(value)
+			case "lower":
+				value = strings.ToLower(value)
+			}
+		}
+		field.SetString(value)
+	}
+}
@@ -0,0 +1,125 @@
+package validation_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"students-crud/internal/models"
+	"students-crud/internal/validation"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name            string
+		input           models.Student
+		expectedStudent models.Student
+		expectedErrors  validation.Errors
+	}{
+		{
+			name:            "OK",
+			input:           models.Student{Name: " Student #1 ", Email: " Student1@Mail.COM "},
+			expectedStudent: models.Student{Name: "Student #1", Email: "student1@mail.com"},
+		},
+		{
+			name:            "Empty Fields",
+			input:           models.Student{Name: "  ", Email: ""},
+			expectedStudent: models.Student{},
+			expectedErrors: validation.Errors{
+				{Field: "name", Rule: "required", Message: "is required"},
+				{Field: "email", Rule: "required", Message: "is required"},
+			},
+		},
+		{
+			name:            "Display Name Is Not Allowed",
+			input:           models.Student{Name: "Student", Email: "Student <s@mail.com>"},
+			expectedStudent: models.Student{Name: "Student", Email: "student <s@mail.com>"},
+			expectedErrors: validation.Errors{
+				{Field: "email", Rule: "rfc5322", Message: "must be a valid email address"},
+			},
+		},
+		{
+			name:            "Too Long",
+			input:           models.Student{Name: strings.Repeat("я", 256), Email: "s@mail.com"},
+			expectedStudent: models.Student{Name: strings.Repeat("я", 256), Email: "s@mail.com"},
+			expectedErrors: validation.Errors{
+				{Field: "name", Rule: "max", Message: "must be at most 255 characters"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			student := testCase.input
+
+			err := validation.Validate(&student)
+
+			assert.Equal(t, testCase.expectedStudent, student)
+			if testCase.expectedErrors == nil {
+				assert.Equal(t, nil, err)
+				return
+			}
+
+			var verrs validation.Errors
+			assert.Equal(t, true, errors.As(err, &verrs))
+			assert.Equal(t, testCase.expectedErrors, verrs)
+		})
+	}
+}
+
+func TestValidate_Numbers(t *testing.T) {
+	course := models.Course{Code: "CS101", Title: "Intro", Term: "2024-1", Credits: 0, Capacity: -1}
+
+	err := validation.Validate(&course)
+
+	var verrs validation.Errors
+	assert.Equal(t, true, errors.As(err, &verrs))
+	assert.Equal(t, validation.Errors{
+		{Field: "credits", Rule: "min", Message: "must be at least 1"},
+		{Field: "capacity", Rule: "min", Message: "must be at least 0"},
+	}, verrs)
+}
+
+func TestValidate_Profile(t *testing.T) {
+	year := 1800
+	birthDate := models.NewDate(2999, time.January, 1)
+	student := models.Student{
+		Name:           "Student",
+		Email:          "s@mail.com",
+		Phone:          " 8 999 123 ",
+		BirthDate:      &birthDate,
+		EnrollmentYear: &year,
+		Status:         "Retired",
+		StudentNumber:  "42",
+	}
+
+	err := validation.Validate(&student)
+
+	var verrs validation.Errors
+	assert.Equal(t, true, errors.As(err, &verrs))
+	assert.Equal(t, validation.Errors{
+		{Field: "birth_date", Rule: "past", Message: "must be in the past"},
+		{Field: "phone", Rule: "phone", Message: "must be a phone number in E.164 format, e.g. +79991234567"},
+		{Field: "enrollment_year", Rule: "min", Message: "must be at least 1900"},
+		{Field: "status", Rule: "oneof", Message: "must be one of: applicant enrolled on_leave graduated expelled"},
+		{Field: "student_number", Rule: "student_number", Message: "must be a student number like 2024-000042"},
+	}, verrs)
+
+	year = 2024
+	birthDate = models.NewDate(2005, time.March, 8)
+	student.Phone = "+79991234567"
+	student.Status = "on_leave"
+	student.StudentNumber = "2024-000042"
+
+	assert.Equal(t, nil, validation.Validate(&student))
+
+	// Номера после 999999 длиннее шести цифр
+	student.StudentNumber = "2024-1000000"
+	assert.Equal(t, nil, validation.Validate(&student))
+
+	student.StudentNumber = "2024-100000000000"
+	assert.Equal(t, true, errors.As(validation.Validate(&student), &verrs))
+}
@@ -1 +1 @@
-DROP TABLE IF EXISTS students;
\ No newline at end of file
+DROP TABLE IF EXISTS students;
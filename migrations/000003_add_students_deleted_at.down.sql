@@ -0,0 +1,7 @@
+DELETE FROM students WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS students_deleted_at_idx;
+DROP INDEX IF EXISTS students_email_active_key;
+ALTER TABLE students ADD CONSTRAINT students_email_key UNIQUE (email);
+
+ALTER TABLE students DROP COLUMN IF EXISTS deleted_at;
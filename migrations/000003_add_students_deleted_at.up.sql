@@ -0,0 +1,7 @@
+ALTER TABLE students ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Email должен быть уникален только среди неудаленных студентов
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS students_email_active_key ON students (email) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS students_deleted_at_idx ON students (deleted_at) WHERE deleted_at IS NOT NULL;
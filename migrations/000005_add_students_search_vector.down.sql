@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS students_search_vector_idx;
+ALTER TABLE students DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,9 @@
+-- Вектор для полнотекстового поиска: имя важнее адреса почты.
+-- Почта разбивается на слова, чтобы находиться по части до или после @
+ALTER TABLE students ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', name), 'A') ||
+        setweight(to_tsvector('simple', translate(email, '@._-+', '     ')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS students_search_vector_idx ON students USING GIN (search_vector);
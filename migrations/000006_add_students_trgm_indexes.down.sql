@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS students_email_trgm_idx;
+DROP INDEX IF EXISTS students_name_trgm_idx;
+
+-- Расширение не удаляется: его могут использовать объекты вне этого приложения
@@ -0,0 +1,5 @@
+-- Триграммы для поиска по неполным и написанным с ошибками именам
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS students_name_trgm_idx ON students USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS students_email_trgm_idx ON students USING GIN (email gin_trgm_ops);
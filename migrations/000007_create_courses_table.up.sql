@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS courses (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(32) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    -- Семестр, например 2024-1; семестры упорядочиваются лексикографически
+    term VARCHAR(32) NOT NULL,
+    credits INTEGER NOT NULL CHECK (credits > 0),
+    -- 0 - без ограничения числа студентов
+    capacity INTEGER NOT NULL DEFAULT 0 CHECK (capacity >= 0),
+    CONSTRAINT courses_code_term_key UNIQUE (code, term)
+);
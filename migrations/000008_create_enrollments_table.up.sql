@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS enrollments (
+    id SERIAL PRIMARY KEY,
+    student_id INTEGER NOT NULL,
+    course_id INTEGER NOT NULL,
+    enrolled_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT enrollments_student_course_key UNIQUE (student_id, course_id),
+    CONSTRAINT enrollments_student_id_fkey FOREIGN KEY (student_id) REFERENCES students (id) ON DELETE CASCADE,
+    -- Курс с записанными студентами удалить нельзя
+    CONSTRAINT enrollments_course_id_fkey FOREIGN KEY (course_id) REFERENCES courses (id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS enrollments_course_id_idx ON enrollments (course_id);
@@ -0,0 +1 @@
+ALTER TABLE students DROP COLUMN IF EXISTS group_id;
@@ -0,0 +1,6 @@
+ALTER TABLE students ADD COLUMN IF NOT EXISTS group_id INTEGER;
+
+-- Группу со студентами удалить нельзя, сначала их нужно перевести
+ALTER TABLE students ADD CONSTRAINT students_group_id_fkey FOREIGN KEY (group_id) REFERENCES groups (id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS students_group_id_idx ON students (group_id);
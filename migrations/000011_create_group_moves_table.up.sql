@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS group_moves (
+    id BIGSERIAL PRIMARY KEY,
+    -- Без внешних ключей: история должна пережить удаление студента и групп
+    student_id INTEGER NOT NULL,
+    -- NULL - студент не состоял в группе или был из нее исключен
+    from_group_id INTEGER,
+    to_group_id INTEGER,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    moved_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS group_moves_student_id_idx ON group_moves (student_id, id DESC);
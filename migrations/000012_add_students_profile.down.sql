@@ -0,0 +1,17 @@
+DROP TRIGGER IF EXISTS students_updated_at ON students;
+DROP FUNCTION IF EXISTS students_touch_updated_at();
+
+ALTER TABLE students
+    DROP COLUMN IF EXISTS first_name,
+    DROP COLUMN IF EXISTS last_name,
+    DROP COLUMN IF EXISTS middle_name,
+    DROP COLUMN IF EXISTS birth_date,
+    DROP COLUMN IF EXISTS phone,
+    DROP COLUMN IF EXISTS enrollment_year,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS student_number,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS updated_at;
+
+DROP FUNCTION IF EXISTS next_student_number();
+DROP SEQUENCE IF EXISTS students_number_seq;
@@ -0,0 +1,46 @@
+-- Порядковый номер занимает от 6 до 11 цифр, чтобы номер помещался в VARCHAR(16)
+CREATE SEQUENCE IF NOT EXISTS students_number_seq MAXVALUE 99999999999;
+
+-- Номер студенческого билета: год выдачи и порядковый номер, например 2024-000042.
+-- Порядковый номер дополняется нулями до шести цифр; более длинные не обрезаются, иначе совпали бы с уже выданными.
+CREATE OR REPLACE FUNCTION next_student_number() RETURNS VARCHAR AS $$
+    SELECT to_char(now(), 'YYYY') || '-' || lpad(n::text, greatest(length(n::text), 6), '0')
+    FROM nextval('students_number_seq') AS n
+$$ LANGUAGE SQL VOLATILE;
+
+-- Существующие студенты получают номера при добавлении колонки
+ALTER TABLE students
+    ADD COLUMN IF NOT EXISTS first_name VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS last_name VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS middle_name VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS birth_date DATE,
+    ADD COLUMN IF NOT EXISTS phone VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS enrollment_year SMALLINT CHECK (enrollment_year BETWEEN 1900 AND 2100),
+    ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'on_leave', 'graduated', 'expelled')),
+    ADD COLUMN IF NOT EXISTS student_number VARCHAR(16) NOT NULL DEFAULT next_student_number()
+        CHECK (student_number ~ '^[0-9]{4}-[0-9]{6,11}$'),
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- Номер не переиспользуется, даже после удаления студента
+ALTER TABLE students ADD CONSTRAINT students_student_number_key UNIQUE (student_number);
+
+-- Время создания существующих записей восстанавливается по журналу аудита
+UPDATE students s SET created_at = a.created_at, updated_at = a.updated_at
+FROM (
+    SELECT student_id, min(created_at) AS created_at, max(created_at) AS updated_at
+    FROM audit_events GROUP BY student_id
+) a
+WHERE a.student_id = s.id;
+
+CREATE OR REPLACE FUNCTION students_touch_updated_at() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS students_updated_at ON students;
+CREATE TRIGGER students_updated_at BEFORE UPDATE ON students
+    FOR EACH ROW EXECUTE FUNCTION students_touch_updated_at();
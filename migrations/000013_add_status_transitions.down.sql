@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS status_transitions;
+
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_status_check;
+UPDATE students SET status = 'active' WHERE status IN ('applicant', 'enrolled');
+ALTER TABLE students
+    ALTER COLUMN status SET DEFAULT 'active',
+    ADD CONSTRAINT students_status_check
+        CHECK (status IN ('active', 'on_leave', 'graduated', 'expelled'));
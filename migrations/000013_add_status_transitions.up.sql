@@ -0,0 +1,21 @@
+-- Статус active разделяется на applicant и enrolled; существующие студенты уже зачислены
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_status_check;
+UPDATE students SET status = 'enrolled' WHERE status = 'active';
+ALTER TABLE students
+    ALTER COLUMN status SET DEFAULT 'enrolled',
+    ADD CONSTRAINT students_status_check
+        CHECK (status IN ('applicant', 'enrolled', 'on_leave', 'graduated', 'expelled'));
+
+CREATE TABLE IF NOT EXISTS status_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    -- Без внешнего ключа: история должна пережить удаление студента
+    student_id INTEGER NOT NULL,
+    from_status VARCHAR(16) NOT NULL,
+    to_status VARCHAR(16) NOT NULL,
+    reason VARCHAR(500) NOT NULL,
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS status_transitions_student_id_idx ON status_transitions (student_id, id DESC);